@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/nikitaksv/gendata/pkg/gen"
+	"github.com/nikitaksv/gendata/pkg/templates"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
@@ -20,29 +23,52 @@ var genCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		g := gen.NewGen()
 
-		tmplDirPath := mustGetString(cmd.Flags(), "tmplDir")
-		entries, err := os.ReadDir(tmplDirPath)
-		if err != nil {
-			return err
-		}
-
 		tmplFiles := make([]*gen.File, 0, 3)
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
+		if tmplDirPath := mustGetString(cmd.Flags(), "tmplDir"); tmplDirPath != "" {
+			entries, err := os.ReadDir(tmplDirPath)
+			if err != nil {
+				return err
 			}
-			if filepath.Ext(entry.Name()) == ".tmpl" {
-				fullPath := filepath.Join(tmplDirPath, entry.Name())
-				f, err := os.Open(fullPath)
-				if err != nil {
-					return errors.WithMessagef(err, "can't open file \"%s\"", fullPath)
+
+			for _, entry := range entries {
+				if entry.IsDir() {
+					continue
 				}
+				if filepath.Ext(entry.Name()) == ".tmpl" {
+					fullPath := filepath.Join(tmplDirPath, entry.Name())
+					f, err := os.Open(fullPath)
+					if err != nil {
+						return errors.WithMessagef(err, "can't open file \"%s\"", fullPath)
+					}
+					tmplFiles = append(tmplFiles, &gen.File{
+						Name: entry.Name(),
+						Body: f,
+					})
+				}
+			}
+		}
+		for _, pack := range mustGetStringSlice(cmd.Flags(), "pack") {
+			packTemplates, err := templates.Pack(pack)
+			if err != nil {
+				return err
+			}
+			for _, tmpl := range packTemplates {
 				tmplFiles = append(tmplFiles, &gen.File{
-					Name: entry.Name(),
-					Body: f,
+					Name: tmpl.Name,
+					Body: bytes.NewBuffer(tmpl.Body),
 				})
 			}
 		}
+		langSettings, err := gen.ReadLangSettings(mustGetStringSlice(cmd.Flags(), "langs")...)
+		if err != nil {
+			return err
+		}
+
+		outputs := mustGetStringSlice(cmd.Flags(), "output")
+		if len(tmplFiles) == 0 && len(outputs) == 0 {
+			return errors.Errorf("no templates, set --tmplDir, --pack (%s) or --output (%s)",
+				strings.Join(templates.Packs(), ", "), strings.Join(gen.Outputs(), ", "))
+		}
 
 		dataFilePath := mustGetString(cmd.Flags(), "dataFile")
 		dataFileBody, err := os.Open(dataFilePath)
@@ -54,18 +80,65 @@ var genCmd = &cobra.Command{
 			Body: dataFileBody,
 		}
 
+		var flatten *gen.Flatten
+		if mustGetBool(cmd.Flags(), "flatten") {
+			flatten = &gen.Flatten{
+				Separator: mustGetString(cmd.Flags(), "flattenSeparator"),
+				Depth:     mustGetInt(cmd.Flags(), "flattenDepth"),
+				Paths:     mustGetStringSlice(cmd.Flags(), "flattenPaths"),
+			}
+		}
+
+		var overridesFile *gen.File
+		if overridesPath := mustGetString(cmd.Flags(), "overrides"); overridesPath != "" {
+			overridesBody, err := os.Open(overridesPath)
+			if err != nil {
+				return errors.WithMessagef(err, "can't open overrides file \"%s\"", overridesPath)
+			}
+			overridesFile = &gen.File{
+				Name: filepath.Base(overridesPath),
+				Body: overridesBody,
+			}
+		}
+
 		generatedFiles, err := g.Gen(context.Background(), &gen.Params{
-			RootClassName:   mustGetString(cmd.Flags(), "rootClassName"),
-			PrefixClassName: mustGetString(cmd.Flags(), "prefixClassName"),
-			SuffixClassName: mustGetString(cmd.Flags(), "suffixClassName"),
-			SortProperties:  mustGetBool(cmd.Flags(), "sort"),
-			Templates:       tmplFiles,
-			Data:            dataFile,
+			LangSettings:         langSettings,
+			RootClassName:        mustGetString(cmd.Flags(), "rootClassName"),
+			PrefixClassName:      mustGetString(cmd.Flags(), "prefixClassName"),
+			SuffixClassName:      mustGetString(cmd.Flags(), "suffixClassName"),
+			SortProperties:       mustGetBool(cmd.Flags(), "sort"),
+			MapMinKeys:           mustGetInt(cmd.Flags(), "mapMinKeys"),
+			MapKeys:              mustGetStringSlice(cmd.Flags(), "mapKeys"),
+			DetectRecursion:      mustGetBool(cmd.Flags(), "recursion"),
+			Discriminators:       mustGetStringSlice(cmd.Flags(), "discriminator"),
+			DetectDiscriminators: mustGetBool(cmd.Flags(), "detectDiscriminator"),
+			DeduplicateClasses:   mustGetString(cmd.Flags(), "dedupe"),
+			ClassNameCollision:   mustGetString(cmd.Flags(), "collision"),
+			Irregulars:           mustGetStringToString(cmd.Flags(), "irregular"),
+			Templates:            tmplFiles,
+			Data:                 dataFile,
+			RootPath:             mustGetString(cmd.Flags(), "root-path"),
+			MultiRoot:            mustGetBool(cmd.Flags(), "multiRoot"),
+			Flatten:              flatten,
+			InlineObjects:        mustGetInt(cmd.Flags(), "inline"),
+			Include:              mustGetStringSlice(cmd.Flags(), "include"),
+			Exclude:              mustGetStringSlice(cmd.Flags(), "exclude"),
+			Overrides:            overridesFile,
+			Package:              mustGetString(cmd.Flags(), "package"),
+			Outputs:              outputs,
+			SQL: &gen.SQL{
+				Dialect:     mustGetString(cmd.Flags(), "sqlDialect"),
+				JSONObjects: mustGetBool(cmd.Flags(), "sqlJSONObjects"),
+			},
 		})
 		if err != nil {
 			return err
 		}
 
+		for _, path := range generatedFiles.Dropped {
+			cmd.PrintErrf("dropped %s\n", path)
+		}
+
 		duplNames := map[string]int{}
 		for _, file := range generatedFiles.RenderedFiles {
 			if count, ok := duplNames[file.Name]; ok {
@@ -80,13 +153,17 @@ var genCmd = &cobra.Command{
 			count := duplNames[file.Name]
 			name := file.Name
 			if count > 0 {
-				name = strconv.Itoa(count) + "_" + name
+				name = filepath.Join(filepath.Dir(name), strconv.Itoa(count)+"_"+filepath.Base(name))
 			}
 			path := filepath.Join(outPath, name)
 			bs, err := io.ReadAll(file.Body)
 			if err != nil {
 				return err
 			}
+			// file names may have directories, ex. package paths
+			if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+				return err
+			}
 			if err = os.WriteFile(path, bs, 0600); err != nil {
 				return err
 			}
@@ -98,27 +175,42 @@ var genCmd = &cobra.Command{
 
 func init() {
 	genCmd.Flags().StringP("tmplDir", "t", "", "Path to directory with template files")
+	genCmd.Flags().StringSliceP("pack", "p", nil, "Bundled template packs, ex. ts/interface, ts/type, ts/zod")
+	genCmd.Flags().StringSliceP("output", "", nil, "Built-in schema outputs: jsonschema, openapi, proto, graphql, sql")
 	genCmd.Flags().StringP("dataFile", "d", "", "Path to data file")
 	genCmd.Flags().StringP("out", "o", ".", "Path to output files directory")
 	genCmd.Flags().StringP("rootClassName", "", "", "Name for root (first) object in data")
 	genCmd.Flags().StringP("prefixClassName", "", "", "Prefix name class")
 	genCmd.Flags().StringP("suffixClassName", "", "", "Suffix name class")
 	genCmd.Flags().BoolP("sort", "", false, "Sort data objects properties")
+	genCmd.Flags().IntP("mapMinKeys", "", 0, "Treat objects with at least this number of keys and homogeneous values as maps")
+	genCmd.Flags().StringSliceP("mapKeys", "", nil, "Property keys of objects to treat as maps")
+	genCmd.Flags().BoolP("recursion", "", false, "Make objects repeating an ancestor shape references to the ancestor class")
+	genCmd.Flags().StringSliceP("discriminator", "", nil, "Keys splitting arrays of objects into variant classes, ex. type")
+	genCmd.Flags().BoolP("detectDiscriminator", "", false, "Detect keys splitting arrays of objects into variant classes")
+	genCmd.Flags().StringP("dedupe", "", "", "Collapse identical nested classes named by strategy: first, shortest, common")
+	genCmd.Flags().StringP("collision", "", "", "Resolve class name collisions by strategy: prefix, suffix, error")
+	genCmd.Flags().StringP("root-path", "", "", "JSON pointer or path of the data node to use as root, ex. /data/user or $.items[*]")
+	genCmd.Flags().BoolP("multiRoot", "", false, "Make a root class of every top-level object of the data")
+	genCmd.Flags().BoolP("flatten", "", false, "Merge nested objects into their parents, ex. coordinates.lon becomes coordinates_lon")
+	genCmd.Flags().StringP("flattenSeparator", "", "_", "Separator of flattened keys")
+	genCmd.Flags().IntP("flattenDepth", "", 0, "Number of flattened levels, 0 is unlimited")
+	genCmd.Flags().StringSliceP("flattenPaths", "", nil, "JSON path patterns of flattened objects, all objects by default")
+	genCmd.Flags().IntP("inline", "", 0, "Make anonymous types of nested objects with at most this number of properties")
+	genCmd.Flags().StringSliceP("include", "", nil, "JSON path patterns of properties to keep, ex. $.user.*")
+	genCmd.Flags().StringSliceP("exclude", "", nil, "JSON path patterns of properties to drop, ex. $..debug")
+	genCmd.Flags().StringP("overrides", "", "", "Path to YAML or JSON file of rename and type overrides by JSON path")
+	genCmd.Flags().StringP("package", "", "", "Package of the generated classes, ex. com.example.models")
+	genCmd.Flags().StringP("sqlDialect", "", "postgres", "Dialect of the sql output: postgres, mysql, sqlite")
+	genCmd.Flags().BoolP("sqlJSONObjects", "", false, "Store nested objects of the sql output in JSON columns instead of child tables")
+	genCmd.Flags().StringToStringP("irregular", "", nil, "Irregular plural=singular words for singular class names")
 
-	if err := genCmd.MarkFlagRequired("tmplDir"); err != nil {
-		log.Fatal(err)
-	}
 	if err := genCmd.MarkFlagRequired("dataFile"); err != nil {
 		log.Fatal(err)
 	}
+	rootCmd.AddCommand(genCmd)
 }
 
-func Execute() {
-	err := genCmd.Execute()
-	if err != nil {
-		os.Exit(1)
-	}
-}
 func mustGetBool(f *flag.FlagSet, name string) bool {
 	v, err := f.GetBool(name)
 	if err != nil {
@@ -134,3 +226,27 @@ func mustGetString(f *flag.FlagSet, name string) string {
 	}
 	return v
 }
+
+func mustGetInt(f *flag.FlagSet, name string) int {
+	v, err := f.GetInt(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func mustGetStringSlice(f *flag.FlagSet, name string) []string {
+	v, err := f.GetStringSlice(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func mustGetStringToString(f *flag.FlagSet, name string) map[string]string {
+	v, err := f.GetStringToString(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nikitaksv/gendata/pkg/gen"
+	"github.com/spf13/cobra"
+)
+
+// langsCmd represents the langs command
+var langsCmd = &cobra.Command{
+	Use:   "langs",
+	Short: "List the predefined languages and the custom ones of --langs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := gen.ReadLangSettings(mustGetStringSlice(cmd.Flags(), "langs")...)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tNAME\tEXTENSIONS\tEXTENDS")
+		// custom languages replace the predefined ones of the same code
+		custom := map[string]*gen.LangSettings{}
+		for _, setting := range settings {
+			custom[setting.Code] = setting
+		}
+		for _, setting := range gen.PredefinedLangSettings {
+			if c, ok := custom[setting.Code]; ok {
+				delete(custom, setting.Code)
+				setting = c
+			}
+			printLang(w, setting)
+		}
+		for _, setting := range settings {
+			if _, ok := custom[setting.Code]; ok {
+				printLang(w, setting)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(langsCmd)
+}
+
+func printLang(w io.Writer, setting *gen.LangSettings) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		setting.Code, setting.Name, strings.Join(setting.FileExtensions, ", "), setting.Extends)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// rootCmd represents the gendata command
+var rootCmd = &cobra.Command{
+	Use:   "gendata",
+	Short: "Generate code of data classes from JSON data by templates",
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringSliceP("langs", "", nil,
+		"Paths to YAML or JSON files or directories of custom language settings")
+}
+
+func Execute() {
+	err := rootCmd.Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
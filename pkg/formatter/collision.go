@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+)
+
+// Strategies of class name collision resolution
+const (
+	// CollisionPrefix prefixes the class name with the names of its parents until it's unique
+	CollisionPrefix = "prefix"
+	// CollisionSuffix appends a number to the class name, ex. Items2
+	CollisionSuffix = "suffix"
+	// CollisionError fails the formatting
+	CollisionError = "error"
+)
+
+// resolveCollisions renames the nested classes whose formatted names are already used by other classes of the tree.
+// The first class keeps its name, so the keys are changed before formatting and references follow their classes.
+// Fixed class names are never changed, other classes give way to them.
+func (f *formatter) resolveCollisions(m *meta.Meta, options *options) error {
+	used := map[string]bool{}
+	for _, class := range m.Classes() {
+		if !class.FixedKey {
+			continue
+		}
+		if used[class.Key.String()] {
+			return errors.Errorf("fixed class name \"%s\" is used by several classes", class.Key.String())
+		}
+		used[class.Key.String()] = true
+	}
+
+	var walk func(class *meta.Meta, parents []meta.Key) error
+	walk = func(class *meta.Meta, parents []meta.Key) error {
+		key := class.Key
+		if key.String() == "" {
+			key = meta.Key(options.rootClassName)
+		}
+		name, err := f.className(key, options)
+		if err != nil {
+			return errors.WithMessagef(err, "can't format name on \"%s\" meta key", key.String())
+		}
+
+		if class.FixedKey {
+			name = key.String()
+		} else if used[name] {
+			switch options.collision {
+			case CollisionError:
+				return errors.Errorf("class name \"%s\" of \"%s\" is already used", name, pathOf(parents, key))
+			case CollisionPrefix:
+				for i := len(parents) - 1; i >= 0 && used[name]; i-- {
+					key = parents[i] + "_" + key
+					if name, err = f.className(key, options); err != nil {
+						return errors.WithMessagef(err, "can't format name on \"%s\" meta key", key.String())
+					}
+				}
+			}
+			base := key
+			for i := 2; used[name]; i++ {
+				key = base + meta.Key(strconv.Itoa(i))
+				if name, err = f.className(key, options); err != nil {
+					return errors.WithMessagef(err, "can't format name on \"%s\" meta key", key.String())
+				}
+			}
+			class.Key = key
+		}
+		used[name] = true
+
+		parents = append(parents, key)
+		for _, property := range class.Properties {
+			if property.Nest != nil {
+				if err := walk(property.Nest, parents[:len(parents):len(parents)]); err != nil {
+					return err
+				}
+			}
+		}
+		for _, variant := range class.Variants {
+			if err := walk(variant, parents[:len(parents)-1:len(parents)-1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return walk(m, nil)
+}
+
+func pathOf(parents []meta.Key, key meta.Key) string {
+	parts := make([]string, 0, len(parents)+1)
+	for _, parent := range parents {
+		parts = append(parts, parent.String())
+	}
+	return strings.Join(append(parts, key.String()), ".")
+}
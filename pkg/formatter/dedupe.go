@@ -0,0 +1,92 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+// Naming strategies of deduplicated classes
+const (
+	// DedupNameFirst names the class by its first occurrence
+	DedupNameFirst = "first"
+	// DedupNameShortest names the class by its shortest name
+	DedupNameShortest = "shortest"
+	// DedupNameCommon names the class by the common ending of its names, ex. billing_address and
+	// shipping_address become address. Falls back to DedupNameFirst when there is no common ending.
+	DedupNameCommon = "common"
+)
+
+// deduplicate keeps the first nested class of every shape and makes other properties of that shape refer to it
+func (f *formatter) deduplicate(m *meta.Meta, naming string) error {
+	for {
+		// properties of every shape in order of first occurrence, so that outer classes are collapsed before
+		// the classes nested in them
+		var fingerprints []string
+		shapes := map[string][]*meta.Property{}
+		for _, class := range m.Classes() {
+			for _, property := range class.Properties {
+				if property.Nest == nil || len(property.Nest.Properties) == 0 {
+					continue
+				}
+				fp := property.Nest.Fingerprint()
+				if _, ok := shapes[fp]; !ok {
+					fingerprints = append(fingerprints, fp)
+				}
+				shapes[fp] = append(shapes[fp], property)
+			}
+		}
+
+		var duplicates []*meta.Property
+		for _, fp := range fingerprints {
+			if len(shapes[fp]) > 1 {
+				duplicates = shapes[fp]
+				break
+			}
+		}
+		if duplicates == nil {
+			return nil
+		}
+
+		class := duplicates[0].Nest
+		class.Key = dedupName(duplicates, naming)
+		for _, property := range duplicates {
+			// a fixed name wins over the naming strategy
+			if property.Nest.FixedKey {
+				class.Key = property.Nest.Key
+				class.FixedKey = true
+				break
+			}
+		}
+		for _, property := range duplicates[1:] {
+			property.Nest = nil
+			property.Ref = class
+		}
+	}
+}
+
+func dedupName(properties []*meta.Property, naming string) meta.Key {
+	name := properties[0].Nest.Key
+	switch naming {
+	case DedupNameShortest:
+		for _, property := range properties[1:] {
+			if len(property.Nest.Key) < len(name) {
+				name = property.Nest.Key
+			}
+		}
+	case DedupNameCommon:
+		common := strings.Split(name.SnakeCase(), "_")
+		for _, property := range properties[1:] {
+			words := strings.Split(property.Nest.Key.SnakeCase(), "_")
+			n := 0
+			for n < len(common) && n < len(words) && common[len(common)-1-n] == words[len(words)-1-n] {
+				n++
+			}
+			common = common[len(common)-n:]
+		}
+		if len(common) > 0 && common[0] != "" {
+			name = meta.Key(strings.Join(common, "_"))
+		}
+	}
+	return name
+}
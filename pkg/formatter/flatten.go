@@ -0,0 +1,104 @@
+package formatter
+
+import (
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+type flattenOptions struct {
+	separator string
+	depth     int
+	patterns  []*meta.PathPattern
+}
+
+func (o *flattenOptions) match(path meta.Path) bool {
+	if len(o.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range o.patterns {
+		if pattern.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten replaces object properties with the properties of their objects keyed by the joined keys,
+// ex. coordinates.lon becomes coordinates_lon. The flattened properties keep their own data keys and paths,
+// ex. lon and $.coordinates.lon, as the flat classes are rows of CSV or SQL rather than data objects.
+// Arrays and maps of objects aren't flattened. Level is the depth of the objects of the meta properties
+// from the root, objects deeper than options.depth aren't flattened.
+func (f *formatter) flatten(m *meta.Meta, options *flattenOptions, level int) {
+	properties := make([]*meta.Property, 0, len(m.Properties))
+	for _, property := range m.Properties {
+		if flattenable(property) && options.deep(level) && options.match(property.Path) {
+			properties = append(properties, f.flattenProperty(property, options, level)...)
+			continue
+		}
+		if property.Nest != nil {
+			f.flatten(property.Nest, options, level+1)
+		}
+		properties = append(properties, property)
+	}
+	m.Properties = properties
+
+	for _, variant := range m.Variants {
+		f.flatten(variant, options, level)
+	}
+}
+
+func (f *formatter) flattenProperty(property *meta.Property, options *flattenOptions, level int) []*meta.Property {
+	flat := make([]*meta.Property, 0, len(property.Nest.Properties))
+	for _, child := range property.Nest.Properties {
+		if !child.FixedKey {
+			child.Key = property.Key + meta.Key(options.separator) + child.Key
+		}
+		if flattenable(child) && options.deep(level+1) {
+			flat = append(flat, f.flattenProperty(child, options, level+1)...)
+			continue
+		}
+		if child.Nest != nil {
+			f.flatten(child.Nest, options, level+2)
+		}
+		flat = append(flat, child)
+	}
+	return flat
+}
+
+// deep reports whether objects at the level are flattened
+func (o *flattenOptions) deep(level int) bool {
+	return o.depth <= 0 || level <= o.depth
+}
+
+func flattenable(property *meta.Property) bool {
+	return property.Nest != nil && property.Type.IsObject() && len(property.Nest.Properties) > 0
+}
+
+// inline makes anonymous types of the object properties whose objects have at most maxProperties scalar
+// properties. Classes referred by other properties keep their own definitions.
+func (f *formatter) inline(m *meta.Meta, maxProperties int) {
+	referred := map[*meta.Meta]bool{}
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			if property.Ref != nil {
+				referred[property.Ref] = true
+			}
+		}
+	}
+
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			if property.Nest == nil || !property.Type.IsObject() || referred[property.Nest] ||
+				len(property.Nest.Properties) > maxProperties {
+				continue
+			}
+			scalar := true
+			for _, p := range property.Nest.Properties {
+				scalar = scalar && !p.Type.HasObject() && !p.Type.IsMap()
+			}
+			if scalar {
+				property.Type.Inline = property.Nest
+				property.Nest = nil
+			}
+		}
+	}
+}
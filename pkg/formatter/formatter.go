@@ -1,12 +1,25 @@
 package formatter
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/nikitaksv/gendata/pkg/meta"
 	"github.com/pkg/errors"
 )
 
 type ClassNameFormatter func(key meta.Key) (string, error)
 
+type PropertyNameFormatter func(key meta.Key) (string, error)
+
+// Sanitizer makes valid identifiers of class and property names
+type Sanitizer interface {
+	// Normalize replaces characters that can't be used in identifiers, it's applied before name formatting
+	Normalize(name string) string
+	// Sanitize escapes reserved words and invalid identifiers, it's applied after name formatting
+	Sanitize(name string, class bool) (string, error)
+}
+
 type Option func(opts *options) error
 
 func WithTypeNameFormatter(formatter *meta.TypeFormatters) Option {
@@ -23,6 +36,22 @@ func WithClassNameFormatter(formatter ClassNameFormatter) Option {
 	}
 }
 
+// WithPropertyNameFormatter formats property names, the original keys stay in meta.Property.OriginalKey
+func WithPropertyNameFormatter(formatter PropertyNameFormatter) Option {
+	return func(opts *options) error {
+		opts.propertyNameFormatter = formatter
+		return nil
+	}
+}
+
+// WithSanitizer makes valid identifiers of class and property names
+func WithSanitizer(sanitizer Sanitizer) Option {
+	return func(opts *options) error {
+		opts.sanitizer = sanitizer
+		return nil
+	}
+}
+
 func WithRootClassName(name string) Option {
 	return func(opts *options) error {
 		opts.rootClassName = name
@@ -44,6 +73,90 @@ func WithSuffixClassName(name string) Option {
 	}
 }
 
+// WithDeduplicateClasses collapses structurally identical nested classes into a single class
+// named by the naming strategy, one of DedupNameFirst, DedupNameShortest or DedupNameCommon.
+// An empty naming disables the deduplication.
+func WithDeduplicateClasses(naming string) Option {
+	return func(opts *options) error {
+		switch naming {
+		case "", DedupNameFirst, DedupNameShortest, DedupNameCommon:
+		default:
+			return errors.Errorf("unknown deduplicated class naming \"%s\"", naming)
+		}
+		opts.dedupNaming = naming
+		return nil
+	}
+}
+
+// WithClassNameCollision resolves class names used by several classes of the tree with the strategy,
+// one of CollisionPrefix, CollisionSuffix or CollisionError. An empty strategy keeps the names as is.
+func WithClassNameCollision(strategy string) Option {
+	return func(opts *options) error {
+		switch strategy {
+		case "", CollisionPrefix, CollisionSuffix, CollisionError:
+		default:
+			return errors.Errorf("unknown class name collision strategy \"%s\"", strategy)
+		}
+		opts.collision = strategy
+		return nil
+	}
+}
+
+// WithSingularClassNames names the classes of array and map elements in singular form
+func WithSingularClassNames(singular bool) Option {
+	return func(opts *options) error {
+		opts.singularClassNames = singular
+		return nil
+	}
+}
+
+// WithIrregulars extends the Irregulars dictionary used by WithSingularClassNames, ex. "octopi": "octopus"
+func WithIrregulars(irregulars map[string]string) Option {
+	return func(opts *options) error {
+		if opts.irregulars == nil {
+			opts.irregulars = make(map[string]string, len(irregulars))
+		}
+		for plural, singular := range irregulars {
+			opts.irregulars[strings.ToLower(plural)] = strings.ToLower(singular)
+		}
+		return nil
+	}
+}
+
+// WithFlatten merges nested objects into their parents down to depth levels, 0 is unlimited.
+// Keys are joined by the separator, "_" by default, ex. coordinates.lon becomes coordinates_lon.
+// Only object properties matched by the patterns are flattened, all of them if there are no patterns.
+// Flat classes are meant for CSV and SQL, their properties keep the data keys of the nested objects,
+// ex. lon, so they don't deserialize the data
+func WithFlatten(separator string, depth int, patterns ...*meta.PathPattern) Option {
+	return func(opts *options) error {
+		if separator == "" {
+			separator = "_"
+		}
+		opts.flatten = &flattenOptions{separator: separator, depth: depth, patterns: patterns}
+		return nil
+	}
+}
+
+// WithInlineObjects makes anonymous types of nested objects with at most maxProperties scalar properties,
+// see meta.Type.Inline. 0 disables it
+func WithInlineObjects(maxProperties int) Option {
+	return func(opts *options) error {
+		opts.inlineObjects = maxProperties
+		return nil
+	}
+}
+
+// WithMultiRoot treats the objects of the root properties as roots formatted together, ex. of
+// {"User": {...}, "Order": {...}}, so the classes are deduplicated and named across all roots, but the roots
+// aren't flattened into the root
+func WithMultiRoot(multiRoot bool) Option {
+	return func(opts *options) error {
+		opts.multiRoot = multiRoot
+		return nil
+	}
+}
+
 func WithSortProperties(sort bool) Option {
 	return func(opts *options) error {
 		opts.sortProperties = sort
@@ -52,12 +165,21 @@ func WithSortProperties(sort bool) Option {
 }
 
 type options struct {
-	typeFormatters     *meta.TypeFormatters
-	classNameFormatter ClassNameFormatter
-	rootClassName      string
-	prefixClassName    string
-	suffixClassName    string
-	sortProperties     bool
+	typeFormatters        *meta.TypeFormatters
+	classNameFormatter    ClassNameFormatter
+	propertyNameFormatter PropertyNameFormatter
+	sanitizer             Sanitizer
+	rootClassName         string
+	prefixClassName       string
+	suffixClassName       string
+	dedupNaming           string
+	collision             string
+	irregulars            map[string]string
+	flatten               *flattenOptions
+	inlineObjects         int
+	sortProperties        bool
+	singularClassNames    bool
+	multiRoot             bool
 }
 
 func (o *options) apply(opts ...Option) error {
@@ -85,6 +207,59 @@ func (f *formatter) Format(m *meta.Meta, opts ...Option) (*meta.Meta, error) {
 		return nil, err
 	}
 
+	if options.flatten != nil {
+		roots := []*meta.Meta{m}
+		if options.multiRoot {
+			roots = roots[:0]
+			for _, property := range m.Properties {
+				if property.Nest != nil {
+					roots = append(roots, property.Nest)
+				}
+			}
+		}
+		for _, root := range roots {
+			f.flatten(root, options.flatten, 1)
+		}
+	}
+
+	if options.dedupNaming != "" {
+		if err := f.deduplicate(m, options.dedupNaming); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.singularClassNames {
+		f.singularizeClassNames(m, options.irregulars)
+	}
+
+	if options.collision != "" {
+		if err := f.resolveCollisions(m, options); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := f.format(m, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// references get the formatted names of their classes
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			if property.Ref != nil {
+				property.Type.SetKey(property.Ref.Key)
+			}
+		}
+	}
+
+	if options.inlineObjects > 0 {
+		f.inline(m, options.inlineObjects)
+	}
+
+	return m, nil
+}
+
+func (f *formatter) format(m *meta.Meta, options *options) (*meta.Meta, error) {
 	if options.sortProperties {
 		m.Sort()
 	}
@@ -93,36 +268,93 @@ func (f *formatter) Format(m *meta.Meta, opts ...Option) (*meta.Meta, error) {
 		m.Key = meta.Key(options.rootClassName)
 	}
 
-	key, err := f.className(m.Key, options)
-	if err != nil {
-		return nil, errors.WithMessagef(err, "can't format name on \"%s\" meta key", m.Key.String())
+	if !m.FixedKey {
+		key, err := f.className(m.Key, options)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "can't format name on \"%s\" meta key", m.Key.String())
+		}
+		m.Key = meta.Key(key)
 	}
-	m.Key = meta.Key(key)
-	m.Type.Key = m.Key
-	m.Type.Formatters = options.typeFormatters
+	m.Type.SetKey(m.Key)
+	m.Type.SetFormatters(options.typeFormatters)
 	for _, property := range m.Properties {
-		property.Type.Formatters = options.typeFormatters
+		property.Type.SetFormatters(options.typeFormatters)
+		if property.OriginalKey == "" {
+			property.OriginalKey = property.Key
+		}
 
-		if property.Type.IsObject() || property.Type.Value == meta.TypeArrayObject {
-			key, err := f.className(property.Key, options)
+		if !property.FixedKey && (options.propertyNameFormatter != nil || options.sanitizer != nil) {
+			key, err := f.propertyName(property.Key, options)
 			if err != nil {
 				return nil, errors.WithMessagef(err, "can't format name on \"%s\" property", property.Key.String())
 			}
 			property.Key = meta.Key(key)
-			property.Type.Key = property.Key
+		}
+		if property.Type.HasObject() {
+			key, err := f.className(property.OriginalKey, options)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "can't format name on \"%s\" property", property.Key.String())
+			}
+			if options.propertyNameFormatter == nil && !property.FixedKey {
+				property.Key = meta.Key(key)
+			}
+			property.Type.SetKey(meta.Key(key))
 		}
 		if property.Nest != nil {
 			var err error
-			property.Nest, err = f.Format(property.Nest, opts...)
+			property.Nest, err = f.format(property.Nest, options)
 			if err != nil {
 				return nil, err
 			}
+			property.Type.SetKey(property.Nest.Key)
+		}
+	}
+
+	if options.propertyNameFormatter != nil || options.sanitizer != nil {
+		uniquePropertyNames(m)
+	}
+
+	for i, variant := range m.Variants {
+		var err error
+		if m.Variants[i], err = f.format(variant, options); err != nil {
+			return nil, err
 		}
 	}
 
 	return m, nil
 }
 
+// uniquePropertyNames appends a number to the property names colliding after formatting, ex. first_name and
+// firstName are firstName and firstName2. Fixed names are never changed, other properties give way to them,
+// and the numbered names skip the names kept by other properties, ex. firstName3 if firstName2 is a property
+func uniquePropertyNames(m *meta.Meta) {
+	used := map[meta.Key]bool{}
+	for _, property := range m.Properties {
+		if property.FixedKey {
+			used[property.Key] = true
+		}
+	}
+	var colliding []*meta.Property
+	for _, property := range m.Properties {
+		if property.FixedKey {
+			continue
+		}
+		if used[property.Key] {
+			colliding = append(colliding, property)
+			continue
+		}
+		used[property.Key] = true
+	}
+	for _, property := range colliding {
+		key := property.Key
+		for i := 2; used[key]; i++ {
+			key = property.Key + meta.Key(strconv.Itoa(i))
+		}
+		property.Key = key
+		used[key] = true
+	}
+}
+
 func (f *formatter) className(name meta.Key, options *options) (string, error) {
 	className := ""
 	if options.prefixClassName != "" {
@@ -130,6 +362,9 @@ func (f *formatter) className(name meta.Key, options *options) (string, error) {
 	} else {
 		className = name.String() + options.suffixClassName
 	}
+	if options.sanitizer != nil {
+		className = options.sanitizer.Normalize(className)
+	}
 	if options.classNameFormatter != nil {
 		var err error
 		className, err = options.classNameFormatter(meta.Key(className))
@@ -137,6 +372,28 @@ func (f *formatter) className(name meta.Key, options *options) (string, error) {
 			return "", err
 		}
 	}
+	if options.sanitizer != nil {
+		return options.sanitizer.Sanitize(className, true)
+	}
 
 	return className, nil
 }
+
+func (f *formatter) propertyName(name meta.Key, options *options) (string, error) {
+	propertyName := name.String()
+	if options.sanitizer != nil {
+		propertyName = options.sanitizer.Normalize(propertyName)
+	}
+	if options.propertyNameFormatter != nil {
+		var err error
+		propertyName, err = options.propertyNameFormatter(meta.Key(propertyName))
+		if err != nil {
+			return "", err
+		}
+	}
+	if options.sanitizer != nil {
+		return options.sanitizer.Sanitize(propertyName, false)
+	}
+
+	return propertyName, nil
+}
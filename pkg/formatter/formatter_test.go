@@ -0,0 +1,249 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/parser"
+)
+
+// pascalCase and camelCase are simple name formatters, ex. first_name is FirstName and firstName
+func pascalCase(key meta.Key) (string, error) {
+	b := &strings.Builder{}
+	for _, word := range strings.FieldsFunc(key.String(), func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String(), nil
+}
+
+func camelCase(key meta.Key) (string, error) {
+	name, _ := pascalCase(key)
+	if name == "" {
+		return "", nil
+	}
+	return strings.ToLower(name[:1]) + name[1:], nil
+}
+
+func format(t *testing.T, data string, opts ...Option) (*meta.Meta, error) {
+	t.Helper()
+	p, err := parser.NewParserJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := p.Parse([]byte(data), parser.WithDiscriminators("type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append([]Option{WithRootClassName("Root"), WithClassNameFormatter(pascalCase)}, opts...)
+	return NewFormatter().Format(m, opts...)
+}
+
+func mustFormat(t *testing.T, data string, opts ...Option) *meta.Meta {
+	t.Helper()
+	m, err := format(t, data, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func classNames(m *meta.Meta) []string {
+	var names []string
+	for _, class := range m.Classes() {
+		names = append(names, class.Key.String())
+	}
+	return names
+}
+
+func TestDeduplicate(t *testing.T) {
+	const data = `{"billing": {"city": "a", "zip": "1"}, "shipping_address": {"city": "b", "zip": "2"}, "home": {"city": "c", "zip": "3"}}`
+	tests := []struct {
+		naming string
+		class  string
+	}{
+		{DedupNameFirst, "Billing"},
+		{DedupNameShortest, "Home"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.naming, func(t *testing.T) {
+			m := mustFormat(t, data, WithDeduplicateClasses(tt.naming))
+			if names := classNames(m); len(names) != 2 || names[1] != tt.class {
+				t.Fatalf("classes %v, want [Root %s]", names, tt.class)
+			}
+			class := m.Classes()[1]
+			for _, p := range m.Properties {
+				if p.Nest != class && p.Ref != class {
+					t.Errorf("property %s isn't of class %s", p.Key, tt.class)
+				}
+				if p.Type.Key.String() != tt.class {
+					t.Errorf("type of property %s is %s, want %s", p.Key, p.Type.Key, tt.class)
+				}
+			}
+		})
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	const data = `{"a": {"meta": {"x": 1}}, "b": {"meta": {"y": "s"}}}`
+	tests := []struct {
+		strategy string
+		classes  []string
+		err      bool
+	}{
+		{"", []string{"Root", "A", "Meta", "B", "Meta"}, false},
+		{CollisionPrefix, []string{"Root", "A", "Meta", "B", "BMeta"}, false},
+		{CollisionSuffix, []string{"Root", "A", "Meta", "B", "Meta2"}, false},
+		{CollisionError, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			m, err := format(t, data, WithClassNameCollision(tt.strategy))
+			if (err != nil) != tt.err {
+				t.Fatalf("error %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if names := classNames(m); strings.Join(names, ",") != strings.Join(tt.classes, ",") {
+				t.Errorf("classes %v, want %v", names, tt.classes)
+			}
+		})
+	}
+}
+
+func identity(key meta.Key) (string, error) {
+	return key.String(), nil
+}
+
+func property(m *meta.Meta, key string) *meta.Property {
+	for _, p := range m.Properties {
+		if string(p.Key) == key {
+			return p
+		}
+	}
+	return nil
+}
+
+func TestFlatten(t *testing.T) {
+	const data = `{"user": {"id": 1, "address": {"city": "x", "geo": {"lat": 1.5}}}, "tags": [{"name": "a"}]}`
+	tests := []struct {
+		name  string
+		depth int
+		// keys are the properties of the root and their data keys
+		keys    map[string]string
+		classes []string
+	}{
+		{
+			name:  "unlimited",
+			depth: 0,
+			keys: map[string]string{
+				"user_id": "id", "user_address_city": "city", "user_address_geo_lat": "lat", "tags": "tags",
+			},
+			classes: []string{"Root", "Tags"},
+		},
+		{
+			name:    "one level",
+			depth:   1,
+			keys:    map[string]string{"user_id": "id", "user_address": "address", "tags": "tags"},
+			classes: []string{"Root", "Address", "Geo", "Tags"},
+		},
+		{
+			name:  "two levels",
+			depth: 2,
+			keys: map[string]string{
+				"user_id": "id", "user_address_city": "city", "user_address_geo": "geo", "tags": "tags",
+			},
+			classes: []string{"Root", "Geo", "Tags"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mustFormat(t, data, WithFlatten("_", tt.depth), WithPropertyNameFormatter(identity))
+			if len(m.Properties) != len(tt.keys) {
+				t.Errorf("%d properties, want %d", len(m.Properties), len(tt.keys))
+			}
+			for key, originalKey := range tt.keys {
+				p := property(m, key)
+				if p == nil {
+					t.Errorf("no property %s", key)
+					continue
+				}
+				if string(p.OriginalKey) != originalKey {
+					t.Errorf("original key of %s is %s, want %s", key, p.OriginalKey, originalKey)
+				}
+			}
+			if names := classNames(m); strings.Join(names, ",") != strings.Join(tt.classes, ",") {
+				t.Errorf("classes %v, want %v", names, tt.classes)
+			}
+		})
+	}
+}
+
+func TestInlineObjects(t *testing.T) {
+	m := mustFormat(t, `{"geo": {"lat": 1.5, "lon": 2.5}, "user": {"id": 1, "name": "a", "email": "b"}}`,
+		WithInlineObjects(2), WithPropertyNameFormatter(identity))
+	if p := property(m, "geo"); p == nil || !p.Type.IsInline() || p.Nest != nil {
+		t.Error("geo isn't inline")
+	}
+	if p := property(m, "user"); p == nil || p.Type.IsInline() || p.Nest == nil {
+		t.Error("user is inline")
+	}
+	if names := classNames(m); strings.Join(names, ",") != "Root,User" {
+		t.Errorf("classes %v, want [Root User]", names)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	m := mustFormat(t, `{"events": [
+		{"type": "click", "x": 1, "meta": {"id": 1}},
+		{"type": "view", "url": "/", "meta": {"id": 2}}
+	]}`, WithSingularClassNames(true), WithDeduplicateClasses(DedupNameFirst), WithClassNameCollision(CollisionPrefix))
+
+	events := property(m, "Events")
+	if events == nil || events.Nest == nil {
+		t.Fatal("no events class")
+	}
+	if len(events.Nest.Variants) != 2 {
+		t.Fatalf("%d variants, want 2", len(events.Nest.Variants))
+	}
+	// the shared meta class is defined once
+	metas := 0
+	for _, name := range classNames(m) {
+		if strings.HasSuffix(name, "Meta") {
+			metas++
+		}
+	}
+	if metas != 1 {
+		t.Errorf("classes %v, want a single meta class", classNames(m))
+	}
+	for _, variant := range events.Nest.Variants {
+		if variant.Key == events.Nest.Key {
+			t.Errorf("variant %s has the name of its base", variant.Key)
+		}
+	}
+}
+
+func TestPropertyNameCollisions(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{`{"first_name": "a", "firstName": "b", "first-name": "c", "last": "d"}`,
+			[]string{"firstName", "firstName2", "firstName3", "last"}},
+		{`{"first_name": "a", "firstName": "b", "firstName2": "c"}`, []string{"firstName", "firstName2", "firstName3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			m := mustFormat(t, tt.data, WithPropertyNameFormatter(camelCase))
+			if len(m.Properties) != len(tt.want) {
+				t.Fatalf("got %d properties, want %d", len(m.Properties), len(tt.want))
+			}
+			for _, key := range tt.want {
+				if property(m, key) == nil {
+					t.Errorf("no property %s", key)
+				}
+			}
+		})
+	}
+}
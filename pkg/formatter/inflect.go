@@ -0,0 +1,138 @@
+package formatter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+// Irregulars is the default dictionary of plural words that don't follow the English inflection rules.
+// Uncountable words map to themselves.
+var Irregulars = map[string]string{
+	"aliases":     "alias",
+	"analyses":    "analysis",
+	"axes":        "axis",
+	"buses":       "bus",
+	"caches":      "cache",
+	"children":    "child",
+	"cookies":     "cookie",
+	"crises":      "crisis",
+	"criteria":    "criterion",
+	"data":        "data",
+	"echoes":      "echo",
+	"equipment":   "equipment",
+	"feet":        "foot",
+	"geese":       "goose",
+	"halves":      "half",
+	"heroes":      "hero",
+	"indices":     "index",
+	"information": "information",
+	"knives":      "knife",
+	"leaves":      "leaf",
+	"lives":       "life",
+	"matrices":    "matrix",
+	"media":       "media",
+	"men":         "man",
+	"metadata":    "metadata",
+	"mice":        "mouse",
+	"movies":      "movie",
+	"news":        "news",
+	"niches":      "niche",
+	"people":      "person",
+	"phenomena":   "phenomenon",
+	"pies":        "pie",
+	"potatoes":    "potato",
+	"quizzes":     "quiz",
+	"series":      "series",
+	"shelves":     "shelf",
+	"species":     "species",
+	"teeth":       "tooth",
+	"theses":      "thesis",
+	"ties":        "tie",
+	"tomatoes":    "tomato",
+	"vertices":    "vertex",
+	"wives":       "wife",
+	"wolves":      "wolf",
+	"women":       "woman",
+	"zombies":     "zombie",
+}
+
+// singularizeClassNames names the classes of array and map elements by the singular form of their keys,
+// ex. addresses: [{...}] becomes class Address.
+func (f *formatter) singularizeClassNames(m *meta.Meta, irregulars map[string]string) {
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			if property.Nest == nil || !(property.Type.IsArray() || property.Type.IsMap()) {
+				continue
+			}
+			if !property.Nest.FixedKey {
+				property.Nest.Key = meta.Key(singularKey(property.Nest.Key.String(), irregulars))
+			}
+			for _, variant := range property.Nest.Variants {
+				if !variant.FixedKey {
+					variant.Key = meta.Key(singularKey(variant.Key.String(), irregulars))
+				}
+			}
+		}
+	}
+}
+
+// singularKey singularizes the last word of the key, ex. billing_addresses, billingAddresses
+func singularKey(key string, irregulars map[string]string) string {
+	rs := []rune(key)
+	start := len(rs)
+	for start > 0 && unicode.IsLower(rs[start-1]) {
+		start--
+	}
+	if start > 0 && unicode.IsUpper(rs[start-1]) {
+		start--
+	}
+	if start == len(rs) {
+		// upper case word
+		for start > 0 && unicode.IsUpper(rs[start-1]) {
+			start--
+		}
+	}
+
+	word := string(rs[start:])
+	lower := strings.ToLower(word)
+	singular := singularWord(lower, irregulars)
+	switch {
+	case singular == lower:
+		return key
+	case word == strings.ToUpper(word):
+		singular = strings.ToUpper(singular)
+	case unicode.IsUpper(rs[start]):
+		singular = strings.ToUpper(singular[:1]) + singular[1:]
+	}
+	return string(rs[:start]) + singular
+}
+
+//nolint:gocyclo
+func singularWord(w string, irregulars map[string]string) string {
+	if s, ok := irregulars[w]; ok {
+		return s
+	}
+	if s, ok := Irregulars[w]; ok {
+		return s
+	}
+
+	switch {
+	case len(w) <= 2:
+		return w
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"), strings.HasSuffix(w, "ches"),
+		strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "zzes"):
+		return strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "uses") && len(w) > 4 && !strings.ContainsRune("aeiou", rune(w[len(w)-5])):
+		// statuses, viruses, but not houses
+		return strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
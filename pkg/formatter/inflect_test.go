@@ -0,0 +1,30 @@
+package formatter
+
+import "testing"
+
+func TestSingularKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"addresses", "address"},
+		{"billing_addresses", "billing_address"},
+		{"billingAddresses", "billingAddress"},
+		{"categories", "category"},
+		{"statuses", "status"},
+		{"houses", "house"},
+		{"boxes", "box"},
+		{"people", "person"},
+		{"news", "news"},
+		{"address", "address"},
+		{"octopi", "octopus"},
+	}
+	irregulars := map[string]string{"octopi": "octopus"}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := singularKey(tt.key, irregulars); got != tt.want {
+				t.Errorf("singular %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package gen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+var (
+	jsIdentifierRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	identifierRe   = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// templateFuncs are the functions available in templates in addition to the meta methods
+var templateFuncs = template.FuncMap{
+	// quote returns a double-quoted string literal, ex. "first name"
+	"quote": func(s interface{}) string {
+		return strconv.Quote(fmt.Sprint(s))
+	},
+	// quoteDollar returns a double-quoted string literal with escaped $ of languages interpolating it,
+	// ex. "\$ref" of Kotlin and Dart
+	"quoteDollar": func(s interface{}) string {
+		return strings.ReplaceAll(strconv.Quote(fmt.Sprint(s)), "$", `\$`)
+	},
+	// quoteKey returns the key as is if it's an identifier, otherwise quoted, ex. id, "first name"
+	"quoteKey": func(s interface{}) string {
+		if str := fmt.Sprint(s); !jsIdentifierRe.MatchString(str) {
+			return strconv.Quote(str)
+		}
+		return fmt.Sprint(s)
+	},
+	// upperFirst upper-cases the first letter, ex. class_ becomes Class_, unlike Key.PascalCase it keeps the rest
+	"upperFirst": func(s interface{}) string {
+		str := fmt.Sprint(s)
+		if str == "" {
+			return str
+		}
+		r, size := utf8.DecodeRuneInString(str)
+		return string(unicode.ToUpper(r)) + str[size:]
+	},
+	// uses reports whether the property types of the classes refer to any of the identifiers,
+	// ex. {{ if uses . "datetime" }}import datetime{{ end }}
+	"uses": func(m *meta.Meta, identifiers ...string) bool {
+		return usesIdentifiers(m.Classes(), identifiers)
+	},
+	// classUses reports whether the property types of the class itself refer to any of the identifiers,
+	// ex. {{ if classUses . "List" }}import java.util.List;{{ end }}
+	"classUses": func(m *meta.Meta, identifiers ...string) bool {
+		return usesIdentifiers([]*meta.Meta{m}, identifiers)
+	},
+}
+
+// renderFuncs returns templateFuncs with the functions of the rendered root and params
+func renderFuncs(pkg string, root *meta.Meta) template.FuncMap {
+	funcs := make(template.FuncMap, len(templateFuncs)+4)
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+	// package returns Params.Package, ex. com.example.models
+	funcs["package"] = func() string {
+		return pkg
+	}
+	// packagePath returns Params.Package as a path, ex. com/example/models
+	funcs["packagePath"] = func() string {
+		return strings.ReplaceAll(pkg, ".", "/")
+	}
+	// baseOf returns the polymorphic class of the variant or nil, ex. {{ with baseOf . }}extends {{ .Key }}{{ end }}
+	funcs["baseOf"] = func(m *meta.Meta) *meta.Meta {
+		for _, class := range root.Classes() {
+			for _, variant := range class.Variants {
+				if variant == m {
+					return class
+				}
+			}
+		}
+		return nil
+	}
+	// isRecursive reports whether the class is nested in itself, ex. {{ if isRecursive . }}
+	funcs["isRecursive"] = func(m *meta.Meta) bool {
+		for _, class := range root.Classes() {
+			for _, property := range class.Properties {
+				if property.Ref == m && property.Type.Recursive {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return funcs
+}
+
+// fileFuncs returns funcs with the functions of the rendered file
+func fileFuncs(funcs template.FuncMap, name string) template.FuncMap {
+	fileFuncs := make(template.FuncMap, len(funcs)+1)
+	for key, fn := range funcs {
+		fileFuncs[key] = fn
+	}
+	// fileName returns the name of the rendered file without the extension, ex. {{ fileName }}.g.dart
+	fileFuncs["fileName"] = func() string {
+		base := path.Base(name)
+		return strings.TrimSuffix(base, path.Ext(base))
+	}
+	return fileFuncs
+}
+
+func usesIdentifiers(classes []*meta.Meta, identifiers []string) bool {
+	for _, class := range classes {
+		for _, property := range class.Properties {
+			for _, found := range identifierRe.FindAllString(property.Type.String(), -1) {
+				for _, identifier := range identifiers {
+					if found == identifier {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/nikitaksv/gendata/pkg/formatter"
 	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/override"
 	parser2 "github.com/nikitaksv/gendata/pkg/parser"
 	"github.com/pkg/errors"
 )
@@ -26,9 +27,68 @@ type Params struct {
 	PrefixClassName string `json:"prefixClassName" xml:"PrefixClassName" yaml:"prefixClassName"`
 	SuffixClassName string `json:"suffixClassName" xml:"SuffixClassName" yaml:"suffixClassName"`
 	// Sort object properties
-	SortProperties bool    `json:"sortProperties" xml:"SortProperties" yaml:"sortProperties"`
-	Templates      []*File `json:"templates"`
-	Data           *File   `json:"data"`
+	SortProperties bool `json:"sortProperties" xml:"SortProperties" yaml:"sortProperties"`
+	// Treat objects with at least MapMinKeys keys and homogeneous values as maps, 0 disables it
+	MapMinKeys int `json:"mapMinKeys" xml:"MapMinKeys" yaml:"mapMinKeys"`
+	// Treat objects under these property keys as maps
+	MapKeys []string `json:"mapKeys" xml:"MapKeys" yaml:"mapKeys"`
+	// Make objects repeating the shape of an ancestor under the same key references to the ancestor class,
+	// ex. replies of comments, so tree-shaped data gets recursive classes
+	DetectRecursion bool `json:"detectRecursion" xml:"DetectRecursion" yaml:"detectRecursion"`
+	// Split arrays of objects into variant classes by the first of these keys, ex. type
+	Discriminators []string `json:"discriminators" xml:"Discriminators" yaml:"discriminators"`
+	// Split arrays of objects into variant classes by a detected discriminator key
+	DetectDiscriminators bool `json:"detectDiscriminators" xml:"DetectDiscriminators" yaml:"detectDiscriminators"`
+	// Collapse structurally identical nested classes, the value is a naming strategy of the shared class:
+	// first, shortest or common. Empty value disables it
+	DeduplicateClasses string `json:"deduplicateClasses" xml:"DeduplicateClasses" yaml:"deduplicateClasses"`
+	// Resolve class names used by several nested classes: prefix, suffix or error. Empty value keeps them as is
+	ClassNameCollision string `json:"classNameCollision" xml:"ClassNameCollision" yaml:"classNameCollision"`
+	// Irregular plural to singular words, used by languages with ConfigMapping.SingularClassNames
+	Irregulars map[string]string `json:"irregulars" xml:"Irregulars" yaml:"irregulars"`
+
+	Templates []*File `json:"templates"`
+	Data      *File   `json:"data"`
+	// RootPath selects the node of the data that becomes the root object, by a JSON pointer, ex. /data/user,
+	// or by a JSON path, ex. $.data.items[*]. Paths of Include, Exclude and Overrides are relative to it
+	RootPath string `json:"rootPath" xml:"RootPath" yaml:"rootPath"`
+	// MultiRoot makes a root class of every top-level object of the data named by its key,
+	// ex. {"User": {...}, "Order": {...}}, the templates are rendered for each root. The roots are formatted
+	// together, identical classes are deduplicated by "first" and collisions are prefixed unless
+	// DeduplicateClasses and ClassNameCollision are set. Outputs render a single schema of all roots
+	MultiRoot bool `json:"multiRoot" xml:"MultiRoot" yaml:"multiRoot"`
+	// Flatten merges nested objects into their parents, ex. coordinates.lon becomes coordinates_lon, for flat
+	// targets such as CSV and SQL. Flattened properties keep their data keys, ex. lon. Nil disables it
+	Flatten *Flatten `json:"flatten" xml:"Flatten" yaml:"flatten"`
+	// Make anonymous types of nested objects with at most InlineObjects scalar properties
+	// in languages with TypeMapping.InlineObject, 0 disables it
+	InlineObjects int `json:"inlineObjects" xml:"InlineObjects" yaml:"inlineObjects"`
+	// Include keeps only the properties matched by these JSON path patterns and their parents,
+	// ex. $.user.*, see meta.PathPattern. Empty Include keeps all properties
+	Include []string `json:"include" xml:"Include" yaml:"include"`
+	// Exclude drops the properties matched by these JSON path patterns, ex. $..debug
+	Exclude []string `json:"exclude" xml:"Exclude" yaml:"exclude"`
+	// Overrides are YAML or JSON rules renaming and retyping properties and classes by JSON path,
+	// see override.Load
+	Overrides *File `json:"overrides"`
+	// Package of the generated classes, ex. com.example.models, available in templates as {{ package }}
+	// and as {{ packagePath }}, ex. com/example/models
+	Package string `json:"package" xml:"Package" yaml:"package"`
+	// Outputs are built-in schemas rendered besides the templates: jsonschema, openapi, proto, graphql and sql,
+	// see Outputs
+	Outputs []string `json:"outputs" xml:"Outputs" yaml:"outputs"`
+	// SQL configures the sql output, nil is PostgreSQL with child tables
+	SQL *SQL `json:"sql" xml:"SQL" yaml:"sql"`
+}
+
+type Flatten struct {
+	// Separator of the joined keys, "_" by default
+	Separator string `json:"separator" xml:"Separator" yaml:"separator"`
+	// Depth is the number of flattened levels from the root, deeper objects keep their classes.
+	// 0 is unlimited
+	Depth int `json:"depth" xml:"Depth" yaml:"depth"`
+	// Paths are JSON path patterns of the flattened object properties, empty Paths flatten all of them
+	Paths []string `json:"paths" xml:"Paths" yaml:"paths"`
 }
 
 type File struct {
@@ -39,6 +99,8 @@ type File struct {
 type RenderResult struct {
 	RenderedFiles []*File       `json:"renderedFiles"`
 	RenderTime    time.Duration `json:"renderTime"`
+	// Dropped are the paths of properties pruned by Params.Include and Params.Exclude
+	Dropped []meta.Path `json:"dropped"`
 }
 
 func NewGen() Gen {
@@ -52,9 +114,14 @@ type gen struct {
 func (_ *gen) Gen(_ context.Context, params *Params) (*RenderResult, error) {
 	beginTs := time.Now()
 
-	if len(params.Templates) == 0 {
+	if len(params.Templates) == 0 && len(params.Outputs) == 0 {
 		return nil, errors.New("templates is empty")
 	}
+	for _, name := range params.Outputs {
+		if _, ok := outputs[name]; !ok {
+			return nil, errors.Errorf("unknown output \"%s\", available outputs: %s", name, strings.Join(Outputs(), ", "))
+		}
+	}
 
 	if params.Data.Body == nil {
 		return nil, errors.Errorf("data \"%s\" is empty", params.Data.Name)
@@ -80,26 +147,44 @@ func (_ *gen) Gen(_ context.Context, params *Params) (*RenderResult, error) {
 		return nil, errors.Errorf("data \"%s\" is empty", params.Data.Name)
 	}
 
-	_meta, err := parser_.Parse(dataBodyBs)
+	_meta, err := parser_.Parse(dataBodyBs,
+		parser2.WithMapMinKeys(params.MapMinKeys),
+		parser2.WithMapKeys(params.MapKeys...),
+		parser2.WithRootPath(params.RootPath),
+		parser2.WithRecursion(params.DetectRecursion),
+		parser2.WithDiscriminators(params.Discriminators...),
+		parser2.WithDiscriminatorDetection(params.DetectDiscriminators),
+	)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "error parsing data file \"%s\"", params.Data.Name)
 	}
 
-	langSettings := append([]*LangSettings{}, PredefinedLangSettings...)
-	for _, setting := range params.LangSettings {
-		if setting.Code == "" {
-			return nil, errors.New("langSetting.code is required")
+	var dropped []meta.Path
+	if len(params.Include) > 0 || len(params.Exclude) > 0 {
+		include, err := compilePathPatterns(params.Include)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid include")
 		}
-		if len(setting.FileExtensions) == 0 {
-			return nil, errors.New("langSetting.fileExtension is required")
+		exclude, err := compilePathPatterns(params.Exclude)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid exclude")
 		}
-		if setting.ConfigMapping == nil {
-			return nil, errors.New("langSetting.configMapping is required")
+		dropped = _meta.Prune(include, exclude)
+	}
+
+	langSettings, err := resolveLangSettings(params.LangSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Overrides != nil && params.Overrides.Body != nil {
+		overrides, err := override.Load(params.Overrides.Body, customTypes(langSettings)...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error loading overrides file \"%s\"", params.Overrides.Name)
 		}
-		if setting.ConfigMapping.TypeMapping == nil {
-			return nil, errors.New("langSetting.configMapping.typeMapping is required")
+		if err := overrides.Apply(_meta); err != nil {
+			return nil, errors.WithMessagef(err, "error applying overrides file \"%s\"", params.Overrides.Name)
 		}
-		langSettings = append(langSettings, setting)
 	}
 
 	const tmplExt = ".tmpl"
@@ -113,6 +198,10 @@ LOOP:
 			return nil, errors.Errorf("template name \"%s\" is not have file extension", file.Name)
 		}
 
+		// the extension before .tmpl takes precedence, ex. schemas.zod.ts.tmpl is zod
+		if exts := strings.Split(strings.TrimSuffix(file.Name, tmplExt), "."); len(exts) > 1 {
+			parts = exts
+		}
 		for _, part := range parts[len(parts)-2:] {
 			for langIdx, setting := range langSettings {
 				for _, ext := range setting.FileExtensions {
@@ -135,6 +224,21 @@ LOOP:
 		}
 	}
 
+	var formatOpts []formatter.Option
+	if params.Flatten != nil {
+		paths, err := compilePathPatterns(params.Flatten.Paths)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid flatten paths")
+		}
+		formatOpts = append(formatOpts, formatter.WithFlatten(params.Flatten.Separator, params.Flatten.Depth, paths...))
+	}
+
+	if params.MultiRoot {
+		if _, err = splitRoots(_meta); err != nil {
+			return nil, errors.WithMessagef(err, "can't split data file \"%s\" into roots", params.Data.Name)
+		}
+	}
+
 	if params.RootClassName == "" {
 		name := params.Data.Name
 		if name != "" {
@@ -145,79 +249,282 @@ LOOP:
 		params.RootClassName = "Root"
 	}
 
+	tmplBodies := make([][]byte, len(params.Templates))
+	for idx, tmpl := range params.Templates {
+		tmplBody := &bytes.Buffer{}
+		if _, err := io.Copy(tmplBody, tmpl.Body); err != nil {
+			return nil, errors.Errorf("can't read template body \"%s\"", tmpl.Name)
+		}
+		if tmplBody.Len() == 0 {
+			return nil, errors.Errorf("template body \"%s\" is empty", tmpl.Name)
+		}
+		tmplBodies[idx] = tmplBody.Bytes()
+	}
+
 	renderedFiles := make([]*File, 0, len(params.Templates))
 	_formatter := formatter.NewFormatter()
 	for langIdx, tmplIdxs := range templateLang {
 		lang := langSettings[langIdx]
 
-		formattedMeta, err := _formatter.Format(_meta.Clone(),
-			formatter.WithPrefixClassName(params.PrefixClassName),
-			formatter.WithSuffixClassName(params.SuffixClassName),
-			formatter.WithRootClassName(params.RootClassName),
-			formatter.WithSortProperties(params.SortProperties),
-
-			formatter.WithClassNameFormatter(lang.ConfigMapping.ClassNameFormatter()),
-			formatter.WithTypeNameFormatter(&meta.TypeFormatters{
-				Type: lang.ConfigMapping.TypeMapping.TypeFormatters(),
-				Doc:  lang.ConfigMapping.TypeDocMapping.TypeFormatters(),
-			}),
-		)
+		formattedRoots, err := formatRoots(_formatter, _meta, params, append(formatOptions(lang, params), formatOpts...))
 		if err != nil {
 			return nil, errors.WithMessagef(err, "formatter error in lang template \"%s\"", lang.Name)
 		}
-
-		for _, idx := range tmplIdxs {
-			tmpl := params.Templates[idx]
-			tmplBody := &bytes.Buffer{}
-			if _, err := io.Copy(tmplBody, tmpl.Body); err != nil {
-				return nil, errors.Errorf("can't read template body \"%s\"", tmpl.Name)
-			}
-			tmplBodyBs := tmplBody.Bytes()
-			if len(tmplBodyBs) == 0 {
-				return nil, errors.Errorf("template body \"%s\" is empty", tmpl.Name)
+		for _, formattedMeta := range formattedRoots {
+			// the types printed by templates can't fail the execution, their errors are reported before
+			if err := formattedMeta.CheckTypes(); err != nil {
+				return nil, errors.WithMessagef(err, "type mapping error in lang template \"%s\"", lang.Name)
 			}
-
-			outName := strings.TrimRight(tmpl.Name, tmplExt)
-			if strings.Contains(outName, "{{") {
-				body := bytes.NewBuffer(nil)
-				t, err := template.New("").Parse(outName)
-				if err != nil {
-					return nil, errors.WithMessagef(err, "incorrect template name \"%s\"", tmpl.Name)
+			funcs := renderFuncs(params.Package, formattedMeta)
+			for _, idx := range tmplIdxs {
+				name := strings.TrimSuffix(params.Templates[idx].Name, tmplExt)
+				if lang.SplitObjectByFiles {
+					files, err := renderClasses(name, tmplBodies[idx], formattedMeta, lang.ConfigMapping.FileNameMapping, funcs)
+					if err != nil {
+						return nil, err
+					}
+					renderedFiles = append(renderedFiles, files...)
+					continue
 				}
-				if err := t.Execute(body, formattedMeta); err != nil {
-					return nil, errors.WithMessagef(err, "incorrect template name \"%s\"", tmpl.Name)
+				if len(formattedRoots) > 1 && !strings.Contains(name, "{{") {
+					// every root needs its own file
+					name = formattedMeta.Key.SnakeCase() + "_" + name
 				}
-				outName = body.String()
-			}
-
-			body := bytes.NewBuffer(nil)
-			t, err := template.New(tmpl.Name).Parse(string(tmplBodyBs))
-			if err != nil {
-				return nil, errors.WithMessagef(err, "incorrect template \"%s\"", tmpl.Name)
-			}
-			if err := t.Execute(body, formattedMeta); err != nil {
-				return nil, errors.WithMessagef(err, "incorrect template \"%s\"", tmpl.Name)
+				file, err := render(name, tmplBodies[idx], formattedMeta, funcs)
+				if err != nil {
+					return nil, err
+				}
+				renderedFiles = append(renderedFiles, file)
 			}
+		}
+	}
 
-			renderedFiles = append(renderedFiles, &File{
-				Name: outName,
-				Body: body,
-			})
+	for _, name := range params.Outputs {
+		out := outputs[name]
+		opts := append(formatOptions(out.lang, params), formatOpts...)
+		if params.ClassNameCollision == "" {
+			// references of schemas must be unambiguous
+			opts = append(opts, formatter.WithClassNameCollision(formatter.CollisionPrefix))
 		}
+		// the schema of multi-root data is a single document of all roots
+		formattedMeta, err := _formatter.Format(_meta.Clone(), append(opts, formatter.WithMultiRoot(params.MultiRoot))...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "formatter error in output \"%s\"", name)
+		}
+		body, err := out.render(formattedMeta, params)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "can't render output \"%s\"", name)
+		}
+		renderedFiles = append(renderedFiles, &File{Name: out.fileName, Body: bytes.NewBuffer(body)})
 	}
 
 	return &RenderResult{
 		RenderedFiles: renderedFiles,
 		RenderTime:    time.Since(beginTs),
+		Dropped:       dropped,
 	}, nil
 }
 
+// formatOptions returns the formatter options of the language and the params
+func formatOptions(lang *LangSettings, params *Params) []formatter.Option {
+	var sanitizer formatter.Sanitizer
+	if lang.Identifiers != nil {
+		sanitizer = lang.Identifiers
+	}
+	inlineObjects := 0
+	if lang.ConfigMapping.TypeMapping.InlineObject != "" {
+		inlineObjects = params.InlineObjects
+	}
+	dedupe, collision := params.DeduplicateClasses, params.ClassNameCollision
+	if params.MultiRoot {
+		// the roots share a package, so their classes must be defined once under distinct names
+		if dedupe == "" {
+			dedupe = formatter.DedupNameFirst
+		}
+		if collision == "" {
+			collision = formatter.CollisionPrefix
+		}
+	}
+
+	return []formatter.Option{
+		formatter.WithPrefixClassName(params.PrefixClassName),
+		formatter.WithSuffixClassName(params.SuffixClassName),
+		formatter.WithRootClassName(params.RootClassName),
+		formatter.WithSortProperties(params.SortProperties),
+		formatter.WithDeduplicateClasses(dedupe),
+		formatter.WithClassNameCollision(collision),
+		formatter.WithSingularClassNames(lang.ConfigMapping.SingularClassNames),
+		formatter.WithIrregulars(params.Irregulars),
+		formatter.WithInlineObjects(inlineObjects),
+
+		formatter.WithClassNameFormatter(lang.ConfigMapping.ClassNameFormatter()),
+		formatter.WithPropertyNameFormatter(lang.ConfigMapping.PropertyNameFormatter()),
+		formatter.WithSanitizer(sanitizer),
+		// doc types fall back to types, types fall back to the common language
+		formatter.WithTypeNameFormatter(&meta.TypeFormatters{
+			Type: layeredTypeFormatter(lang.ConfigMapping.TypeMapping, commonTypeMapping()),
+			Doc: layeredTypeFormatter(lang.ConfigMapping.TypeDocMapping, lang.ConfigMapping.TypeMapping,
+				commonTypeMapping()),
+		}),
+	}
+}
+
+// renderClasses executes the template with every class of the meta, a file per class. The file is named by
+// the template name if it's a template, otherwise by fileNameMapping or by the class name and the template
+// extension, ex. Address.php
+func renderClasses(name string, body []byte, m *meta.Meta, fileNameMapping string, funcs template.FuncMap) ([]*File, error) {
+	if !strings.Contains(name, "{{") {
+		if fileNameMapping != "" {
+			name = fileNameMapping
+		} else {
+			name = "{{ .Key }}" + filepath.Ext(name)
+		}
+	}
+
+	classes := m.Classes()
+	files := make([]*File, 0, len(classes))
+	for _, class := range classes {
+		file, err := render(name, body, class, funcs)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// render executes the template and its name with the meta, the name is a template too, ex. {{ .Key }}.go
+func render(name string, body []byte, m *meta.Meta, funcs template.FuncMap) (*File, error) {
+	outName := name
+	if strings.Contains(name, "{{") {
+		b := bytes.NewBuffer(nil)
+		t, err := template.New("").Funcs(funcs).Parse(name)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "incorrect template name \"%s\"", name)
+		}
+		if err := t.Execute(b, m); err != nil {
+			return nil, errors.WithMessagef(err, "incorrect template name \"%s\"", name)
+		}
+		outName = b.String()
+	}
+
+	b := bytes.NewBuffer(nil)
+	t, err := template.New(name).Funcs(fileFuncs(funcs, outName)).Parse(string(body))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "incorrect template \"%s\"", name)
+	}
+	if err := t.Execute(b, m); err != nil {
+		return nil, errors.WithMessagef(err, "incorrect template \"%s\"", name)
+	}
+
+	return &File{
+		Name: outName,
+		Body: b,
+	}, nil
+}
+
+// formatRoots formats the meta and returns its roots. The roots of multi-root data are formatted together,
+// so the classes shared by several roots are defined once
+func formatRoots(f formatter.Formatter, m *meta.Meta, params *Params, opts []formatter.Option) ([]*meta.Meta, error) {
+	if !params.MultiRoot {
+		formattedMeta, err := f.Format(m.Clone(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		return []*meta.Meta{formattedMeta}, nil
+	}
+
+	formattedMeta, err := f.Format(m.Clone(), append(opts, formatter.WithMultiRoot(true))...)
+	if err != nil {
+		return nil, err
+	}
+	return splitRoots(formattedMeta)
+}
+
+// splitRoots makes a root of every top-level object of the meta named by its key
+func splitRoots(m *meta.Meta) ([]*meta.Meta, error) {
+	roots := make([]*meta.Meta, 0, len(m.Properties))
+	for _, property := range m.Properties {
+		if property.Ref != nil && property.Type.IsObject() {
+			// a root identical to a previous one is deduplicated
+			continue
+		}
+		if property.Nest == nil || !property.Type.IsObject() {
+			return nil, errors.Errorf("top-level value \"%s\" is not an object", property.Key.String())
+		}
+		roots = append(roots, property.Nest)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("data has no top-level objects")
+	}
+	return roots, nil
+}
+
+func compilePathPatterns(patterns []string) ([]*meta.PathPattern, error) {
+	compiled := make([]*meta.PathPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := meta.CompilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
 type LangSettings struct {
 	ConfigMapping      *ConfigMapping `json:"configMapping"  yaml:"configMapping" xml:"ConfigMapping"`
 	Code               string         `json:"code" yaml:"code" xml:"Code"`
 	Name               string         `json:"name" yaml:"name" xml:"Name"`
 	FileExtensions     []string       `json:"fileExtensions" yaml:"fileExtensions" xml:"FileExtensions"`
 	SplitObjectByFiles bool           `json:"splitObjectByFiles" yaml:"splitObjectByFiles" xml:"SplitObjectByFiles"`
+	// Identifiers are rules of valid class and property names, nil keeps names as is
+	Identifiers *IdentifierRules `json:"identifiers" yaml:"identifiers" xml:"Identifiers"`
+	// Extends is the code of the language whose settings are overridden, see LoadLangSettings
+	Extends string `json:"extends,omitempty" yaml:"extends,omitempty" xml:"Extends,omitempty"`
+}
+
+// commonTypeMapping is the last fallback of type mappings
+func commonTypeMapping() *TypeMapping {
+	return PredefinedLangSettings[0].ConfigMapping.TypeMapping
+}
+
+// resolveLangSettings returns the predefined languages and the custom ones, the common language is the first.
+// Custom languages replace the predefined ones of the same code and take precedence in the template extensions
+func resolveLangSettings(custom []*LangSettings) ([]*LangSettings, error) {
+	langSettings := []*LangSettings{PredefinedLangSettings[0]}
+	codes := map[string]bool{}
+	for _, setting := range custom {
+		if err := setting.Validate(); err != nil {
+			return nil, err
+		}
+		if setting.Code == PredefinedLangSettings[0].Code {
+			langSettings[0] = setting
+		} else {
+			langSettings = append(langSettings, setting)
+		}
+		codes[setting.Code] = true
+	}
+	for _, setting := range PredefinedLangSettings[1:] {
+		if !codes[setting.Code] {
+			langSettings = append(langSettings, setting)
+		}
+	}
+	return langSettings, nil
+}
+
+// customTypes returns the keys of TypeMapping.Custom of the languages, the types overrides may set
+func customTypes(langSettings []*LangSettings) []string {
+	var types []string
+	for _, setting := range langSettings {
+		if setting.ConfigMapping == nil || setting.ConfigMapping.TypeMapping == nil {
+			continue
+		}
+		for key := range setting.ConfigMapping.TypeMapping.Custom {
+			types = append(types, key)
+		}
+	}
+	return types
 }
 
 var PredefinedLangSettings = []*LangSettings{
@@ -228,22 +535,28 @@ var PredefinedLangSettings = []*LangSettings{
 		SplitObjectByFiles: false,
 		ConfigMapping: &ConfigMapping{
 			TypeMapping: &TypeMapping{
-				Array:       "[]",
-				ArrayBool:   "[]",
-				ArrayFloat:  "[]",
-				ArrayInt:    "[]",
-				ArrayObject: "[]",
-				ArrayString: "[]",
-				Bool:        "bool",
-				Float:       "float",
-				Int:         "int",
-				Null:        "null",
-				Object:      "{{ .Key.CamelCase}}",
-				String:      "string",
-				Time:        "time",
-				Date:        "date",
-				DateTime:    "datetime",
-				Duration:    "duration",
+				Array:        "[]",
+				ArrayBool:    "[]",
+				ArrayFloat:   "[]",
+				ArrayInt:     "[]",
+				ArrayBigInt:  "[]",
+				ArrayObject:  "[]",
+				ArrayArray:   "[]",
+				ArrayString:  "[]",
+				Bool:         "bool",
+				Float:        "float",
+				Int:          "int",
+				BigInt:       "bigint",
+				Null:         "null",
+				Object:       "{{ .Key.CamelCase}}",
+				Map:          "map",
+				String:       "string",
+				Time:         "time",
+				Date:         "date",
+				DateTime:     "datetime",
+				Duration:     "duration",
+				InlineObject: "object",
+				Custom:       map[string]string{"uuid": "uuid"},
 			},
 			TypeDocMapping:   nil,
 			ClassNameMapping: "{{ .Key.PascalCase }}",
@@ -254,27 +567,41 @@ var PredefinedLangSettings = []*LangSettings{
 		Name:               "GoLang",
 		FileExtensions:     []string{"go"},
 		SplitObjectByFiles: false,
+		Identifiers: &IdentifierRules{
+			ReservedWords: goKeywords,
+			// names must start with an upper case letter to be exported
+			DigitPrefix: "X",
+			ASCII:       true,
+		},
 		ConfigMapping: &ConfigMapping{
 			TypeMapping: &TypeMapping{
-				Array:       "[]interface{}",
-				ArrayBool:   "[]bool",
-				ArrayFloat:  "[]float64",
-				ArrayInt:    "[]int",
-				ArrayObject: "[]*{{ .Key.PascalCase }}",
-				ArrayString: "[]string",
-				Bool:        "bool",
-				Float:       "float64",
-				Int:         "int",
-				Null:        "interface{}",
-				Object:      "{{ .Key.PascalCase}}",
-				String:      "string",
-				Time:        "time.Time",
-				Date:        "time.Time",
-				DateTime:    "time.Time",
-				Duration:    "time.Duration",
+				Array:        "[]interface{}",
+				ArrayBool:    "[]bool",
+				ArrayFloat:   "[]float64",
+				ArrayInt:     "[]int",
+				ArrayBigInt:  "[]*big.Int",
+				ArrayObject:  "[]*{{ .Key }}",
+				ArrayArray:   "[]{{ .Elem }}",
+				ArrayString:  "[]string",
+				Bool:         "bool",
+				Float:        "float64",
+				Int:          "int",
+				BigInt:       "*big.Int",
+				Null:         "interface{}",
+				Object:       "{{ if .Recursive }}*{{ end }}{{ .Key }}",
+				Map:          "map[string]{{ if .Elem.IsObject }}*{{ end }}{{ .Elem }}",
+				String:       "string",
+				Time:         "time.Time",
+				Date:         "time.Time",
+				DateTime:     "time.Time",
+				Duration:     "time.Duration",
+				InlineObject: goInlineObject,
+				Custom:       map[string]string{"uuid": "uuid.UUID"},
 			},
-			TypeDocMapping:   nil,
-			ClassNameMapping: "{{ .Key.PascalCase }}",
+			TypeDocMapping:      nil,
+			ClassNameMapping:    "{{ .Key.PascalCase }}",
+			PropertyNameMapping: "{{ .Key.PascalCase }}",
+			SingularClassNames:  true,
 		},
 	},
 	{
@@ -282,27 +609,41 @@ var PredefinedLangSettings = []*LangSettings{
 		Name:               "GoLang > v1.20",
 		FileExtensions:     []string{"go"},
 		SplitObjectByFiles: false,
+		Identifiers: &IdentifierRules{
+			ReservedWords: goKeywords,
+			// names must start with an upper case letter to be exported
+			DigitPrefix: "X",
+			ASCII:       true,
+		},
 		ConfigMapping: &ConfigMapping{
 			TypeMapping: &TypeMapping{
-				Array:       "[]any",
-				ArrayBool:   "[]bool",
-				ArrayFloat:  "[]float64",
-				ArrayInt:    "[]int",
-				ArrayObject: "[]*{{ .Key.PascalCase }}",
-				ArrayString: "[]string",
-				Bool:        "bool",
-				Float:       "float64",
-				Int:         "int",
-				Null:        "any",
-				Object:      "{{ .Key.PascalCase}}",
-				String:      "string",
-				Time:        "time.Time",
-				Date:        "time.Time",
-				DateTime:    "time.Time",
-				Duration:    "time.Duration",
+				Array:        "[]any",
+				ArrayBool:    "[]bool",
+				ArrayFloat:   "[]float64",
+				ArrayInt:     "[]int",
+				ArrayBigInt:  "[]*big.Int",
+				ArrayObject:  "[]*{{ .Key }}",
+				ArrayArray:   "[]{{ .Elem }}",
+				ArrayString:  "[]string",
+				Bool:         "bool",
+				Float:        "float64",
+				Int:          "int",
+				BigInt:       "*big.Int",
+				Null:         "any",
+				Object:       "{{ if .Recursive }}*{{ end }}{{ .Key }}",
+				Map:          "map[string]{{ if .Elem.IsObject }}*{{ end }}{{ .Elem }}",
+				String:       "string",
+				Time:         "time.Time",
+				Date:         "time.Time",
+				DateTime:     "time.Time",
+				Duration:     "time.Duration",
+				InlineObject: goInlineObject,
+				Custom:       map[string]string{"uuid": "uuid.UUID"},
 			},
-			TypeDocMapping:   nil,
-			ClassNameMapping: "{{ .Key.PascalCase }}",
+			TypeDocMapping:      nil,
+			ClassNameMapping:    "{{ .Key.PascalCase }}",
+			PropertyNameMapping: "{{ .Key.PascalCase }}",
+			SingularClassNames:  true,
 		},
 	},
 	{
@@ -310,53 +651,97 @@ var PredefinedLangSettings = []*LangSettings{
 		Name:               "PHP",
 		FileExtensions:     []string{"php"},
 		SplitObjectByFiles: true,
+		Identifiers: &IdentifierRules{
+			ReservedClassNames: phpReservedClassNames,
+			CaseInsensitive:    true,
+		},
 		ConfigMapping: &ConfigMapping{
 			TypeMapping: &TypeMapping{
-				Array:       "array",
-				ArrayBool:   "array",
-				ArrayFloat:  "array",
-				ArrayInt:    "array",
-				ArrayObject: "array",
-				ArrayString: "array",
-				Bool:        "bool",
-				Float:       "float",
-				Int:         "int",
-				Null:        "null",
-				Object:      "{{ .Key.PascalCase}}",
-				String:      "string",
-				Time:        "\\DateTime",
-				Date:        "\\DateTime",
-				DateTime:    "\\DateTime",
-				Duration:    "\\DateInterval",
+				Array:        "array",
+				ArrayBool:    "array",
+				ArrayFloat:   "array",
+				ArrayInt:     "array",
+				ArrayBigInt:  "array",
+				ArrayObject:  "array",
+				ArrayArray:   "array",
+				ArrayString:  "array",
+				Bool:         "bool",
+				Float:        "float",
+				Int:          "int",
+				BigInt:       "string",
+				Null:         "null",
+				Object:       "{{ .Key }}",
+				Map:          "array",
+				String:       "string",
+				Time:         "\\DateTime",
+				Date:         "\\DateTime",
+				DateTime:     "\\DateTime",
+				Duration:     "\\DateInterval",
+				InlineObject: "array",
+				Custom:       map[string]string{"uuid": "string"},
 			},
 			TypeDocMapping: &TypeMapping{
-				Array:       "array",
-				ArrayBool:   "bool[]",
-				ArrayFloat:  "float[]",
-				ArrayInt:    "int[]",
-				ArrayObject: "{{ .Key.PascalCase }}[]",
-				ArrayString: "string[]",
-				Bool:        "bool",
-				Float:       "float",
-				Int:         "int",
-				Null:        "null",
-				Object:      "{{ .Key.PascalCase}}",
-				String:      "string",
-				Time:        "\\DateTime",
-				Date:        "\\DateTime",
-				DateTime:    "\\DateTime",
-				Duration:    "\\DateInterval",
+				Array:        "array",
+				ArrayBool:    "bool[]",
+				ArrayFloat:   "float[]",
+				ArrayInt:     "int[]",
+				ArrayBigInt:  "string[]",
+				ArrayObject:  "{{ .Key }}[]",
+				ArrayArray:   "{{ .Elem.Doc }}[]",
+				ArrayString:  "string[]",
+				Bool:         "bool",
+				Float:        "float",
+				Int:          "int",
+				BigInt:       "string",
+				Null:         "null",
+				Object:       "{{ .Key }}",
+				Map:          "array<string, {{ .Elem.Doc }}>",
+				String:       "string",
+				Time:         "\\DateTime",
+				Date:         "\\DateTime",
+				DateTime:     "\\DateTime",
+				Duration:     "\\DateInterval",
+				InlineObject: phpDocInlineObject,
+				Custom:       map[string]string{"uuid": "string"},
 			},
-			ClassNameMapping: "{{ .Key.PascalCase }}",
+			ClassNameMapping:    "{{ .Key.PascalCase }}",
+			PropertyNameMapping: "{{ .Key.CamelCase }}",
+			SingularClassNames:  true,
 		},
 	},
+	tsLangSettings,
+	zodLangSettings,
+	pyLangSettings,
+	javaLangSettings,
+	ktLangSettings,
+	csLangSettings,
+	rsLangSettings,
+	swiftLangSettings,
+	dartLangSettings,
 }
 
+// goInlineObject is an anonymous struct, ex. struct { Lon float64 `json:"lon"`; Lat float64 `json:"lat"` }
+const goInlineObject = "struct { {{ range $i, $p := .Inline.Properties }}{{ if $i }}; {{ end }}" +
+	"{{ $p.Key }} {{ $p.Type }} `json:\"{{ $p.OriginalKey }}\"`{{ end }} }"
+
+// phpDocInlineObject is an array shape, ex. array{lon: float, lat: float}
+const phpDocInlineObject = "array{{ \"{\" }}{{ range $i, $p := .Inline.Properties }}{{ if $i }}, {{ end }}" +
+	"{{ $p.OriginalKey }}: {{ $p.Type.Doc }}{{ end }}{{ \"}\" }}"
+
 type ConfigMapping struct {
-	TypeMapping      *TypeMapping `json:"typeMapping" xml:"TypeMapping" yaml:"typeMapping"`
+	TypeMapping *TypeMapping `json:"typeMapping" xml:"TypeMapping" yaml:"typeMapping"`
+	// TypeDocMapping formats doc types, {{ .Type.Doc }}, ex. int[] of PHPDoc. Nil mapping and empty types
+	// fall back to TypeMapping, then to the common language
 	TypeDocMapping   *TypeMapping `json:"typeDocMapping" xml:"TypeDocMapping" yaml:"typeDocMapping"`
 	ClassNameMapping string       `json:"classNameMapping" xml:"ClassNameMapping" yaml:"classNameMapping"`
-	FileNameMapping  string       `json:"fileNameMapping" xml:"FileNameMapping" yaml:"fileNameMapping"`
+	// FileNameMapping names the files of classes rendered one per file by LangSettings.SplitObjectByFiles,
+	// ex. "{{ with packagePath }}{{ . }}/{{ end }}{{ .Key }}.java". Empty mapping names them by the class name
+	// and the template extension
+	FileNameMapping string `json:"fileNameMapping" xml:"FileNameMapping" yaml:"fileNameMapping"`
+	// PropertyNameMapping formats property names, ex. "{{ .Key.PascalCase }}". Empty mapping keeps data keys
+	PropertyNameMapping string `json:"propertyNameMapping" xml:"PropertyNameMapping" yaml:"propertyNameMapping"`
+	// Name classes of array and map elements in singular form, ex. addresses: [{...}] becomes class Address
+	SingularClassNames bool `json:"singularClassNames" xml:"SingularClassNames" yaml:"singularClassNames"`
 }
 
 func (m ConfigMapping) ClassNameFormatter() formatter.ClassNameFormatter {
@@ -375,23 +760,53 @@ func (m ConfigMapping) ClassNameFormatter() formatter.ClassNameFormatter {
 	}
 }
 
+func (m ConfigMapping) PropertyNameFormatter() formatter.PropertyNameFormatter {
+	if m.PropertyNameMapping == "" {
+		return nil
+	}
+	return func(key meta.Key) (string, error) {
+		tmpl, err := template.New("").Parse(m.PropertyNameMapping)
+		if err != nil {
+			return "", errors.WithMessage(err, "PropertyNameFormatter template parse error")
+		}
+		b := &strings.Builder{}
+		if err := tmpl.Execute(b, struct {
+			Key meta.Key
+		}{key}); err != nil {
+			return "", errors.WithMessage(err, "PropertyNameFormatter template execute error")
+		}
+		return b.String(), nil
+	}
+}
+
 type TypeMapping struct {
 	Array       string `json:"array" yaml:"array" xml:"Array"`
 	ArrayBool   string `json:"arrayBool" yaml:"arrayBool" xml:"ArrayBool"`
 	ArrayFloat  string `json:"arrayFloat" yaml:"arrayFloat" xml:"ArrayFloat"`
 	ArrayInt    string `json:"arrayInt" yaml:"arrayInt" xml:"ArrayInt"`
+	ArrayBigInt string `json:"arrayBigInt" yaml:"arrayBigInt" xml:"ArrayBigInt"`
 	ArrayObject string `json:"arrayObject" yaml:"arrayObject" xml:"ArrayObject"`
+	ArrayArray  string `json:"arrayArray" yaml:"arrayArray" xml:"ArrayArray"`
 	ArrayString string `json:"arrayString" yaml:"arrayString" xml:"ArrayString"`
 	Bool        string `json:"bool" yaml:"bool" xml:"Bool"`
 	Float       string `json:"float" yaml:"float" xml:"Float"`
 	Int         string `json:"int" yaml:"int" xml:"Int"`
+	BigInt      string `json:"bigInt" yaml:"bigInt" xml:"BigInt"`
 	Null        string `json:"null" yaml:"null" xml:"Null"`
 	Object      string `json:"object" yaml:"object" xml:"Object"`
+	Map         string `json:"map" yaml:"map" xml:"Map"`
 	String      string `json:"string" yaml:"string" xml:"String"`
 	Time        string `json:"time" yaml:"time" xml:"Time"`
 	Date        string `json:"date" yaml:"date" xml:"Date"`
 	DateTime    string `json:"dateTime" yaml:"dateTime" xml:"DateTime"`
 	Duration    string `json:"duration" yaml:"duration" xml:"Duration"`
+	// Nullable wraps the types of nullable values, ex. "{{ . }} | null", where . is the type.
+	// Empty mapping keeps the types as is
+	Nullable string `json:"nullable" yaml:"nullable" xml:"Nullable"`
+	// InlineObject is an anonymous object type, see meta.Type.Inline. Empty mapping disables inline objects
+	InlineObject string `json:"inlineObject" yaml:"inlineObject" xml:"InlineObject"`
+	// Custom maps types set by overrides, ex. "uuid": "uuid.UUID"
+	Custom map[string]string `json:"custom" yaml:"custom" xml:"Custom"`
 }
 
 func (m *TypeMapping) GetType(key string) (string, error) {
@@ -404,8 +819,12 @@ func (m *TypeMapping) GetType(key string) (string, error) {
 		return m.ArrayFloat, nil
 	case meta.TypeArrayInt:
 		return m.ArrayInt, nil
+	case meta.TypeArrayBigInt:
+		return m.ArrayBigInt, nil
 	case meta.TypeArrayObject:
 		return m.ArrayObject, nil
+	case meta.TypeArrayArray:
+		return m.ArrayArray, nil
 	case meta.TypeArrayString:
 		return m.ArrayString, nil
 	case meta.TypeBool:
@@ -414,10 +833,14 @@ func (m *TypeMapping) GetType(key string) (string, error) {
 		return m.Float, nil
 	case meta.TypeInt:
 		return m.Int, nil
+	case meta.TypeBigInt:
+		return m.BigInt, nil
 	case meta.TypeNull:
 		return m.Null, nil
 	case meta.TypeObject:
 		return m.Object, nil
+	case meta.TypeMap:
+		return m.Map, nil
 	case meta.TypeString:
 		return m.String, nil
 	case meta.TypeTime:
@@ -429,6 +852,9 @@ func (m *TypeMapping) GetType(key string) (string, error) {
 	case meta.TypeDuration:
 		return m.Duration, nil
 	}
+	if typ, ok := m.Custom[key]; ok {
+		return typ, nil
+	}
 	return "", errors.Errorf("invalid TypeMapping key %s", key)
 }
 
@@ -436,19 +862,63 @@ func (m *TypeMapping) TypeFormatters() meta.TypeFormatter {
 	if m == nil {
 		return nil
 	}
-	return func(t meta.Type) string {
-		typ, err := m.GetType(t.Value)
-		if err != nil {
-			return errors.WithMessage(err, "TypeFormatter error").Error()
+	return layeredTypeFormatter(m)
+}
+
+// layeredTypeFormatter formats types by the first mapping with a non-empty type, ex. TypeDocMapping,
+// TypeMapping and the mapping of the common language. Nil mappings are skipped, the type is wrapped
+// by the Nullable of its mapping
+func layeredTypeFormatter(mappings ...*TypeMapping) meta.TypeFormatter {
+	return func(t meta.Type) (string, error) {
+		var m *TypeMapping
+		var typ string
+		var mappingErr error
+		known := false
+		for _, mapping := range mappings {
+			if mapping == nil {
+				continue
+			}
+			mappingTyp, err := mapping.GetType(t.Value)
+			if t.IsInline() && mapping.InlineObject != "" {
+				mappingTyp, err = mapping.InlineObject, nil
+			}
+			if err != nil {
+				mappingErr = err
+				continue
+			}
+			known = true
+			if mappingTyp != "" {
+				m, typ = mapping, mappingTyp
+				break
+			}
+		}
+		if !known && mappingErr != nil {
+			return "", errors.WithMessage(mappingErr, "TypeFormatter error")
 		}
-		tmpl, err := template.New("").Parse(typ)
+		if m == nil {
+			// the type is empty in all mappings
+			return "", nil
+		}
+		tmpl, err := template.New("").Funcs(templateFuncs).Parse(typ)
 		if err != nil {
-			return errors.WithMessage(err, "TypeFormatter template parse error").Error()
+			return "", errors.WithMessage(err, "TypeFormatter template parse error")
 		}
 		b := &strings.Builder{}
 		if err := tmpl.Execute(b, t); err != nil {
-			return errors.WithMessage(err, "TypeFormatter template execute error").Error()
+			return "", errors.WithMessage(err, "TypeFormatter template execute error")
+		}
+		if !t.Nullable || m.Nullable == "" {
+			return b.String(), nil
+		}
+
+		tmpl, err = template.New("").Funcs(templateFuncs).Parse(m.Nullable)
+		if err != nil {
+			return "", errors.WithMessage(err, "TypeFormatter nullable template parse error")
+		}
+		nb := &strings.Builder{}
+		if err := tmpl.Execute(nb, b.String()); err != nil {
+			return "", errors.WithMessage(err, "TypeFormatter nullable template execute error")
 		}
-		return b.String()
+		return nb.String(), nil
 	}
 }
@@ -0,0 +1,700 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/templates"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// testFile reads a file of the testdata directory in the repository root
+func testFile(t *testing.T, name string) *File {
+	t.Helper()
+	bs, err := os.ReadFile(filepath.Join("..", "..", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &File{Name: name, Body: bytes.NewBuffer(bs)}
+}
+
+func stringFile(name, body string) *File {
+	return &File{Name: name, Body: bytes.NewBufferString(body)}
+}
+
+func generate(t *testing.T, params *Params) (map[string]string, error) {
+	t.Helper()
+	result, err := NewGen().Gen(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(result.RenderedFiles))
+	for _, file := range result.RenderedFiles {
+		bs, err := io.ReadAll(file.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name] = string(bs)
+	}
+	return files, nil
+}
+
+func mustGenerate(t *testing.T, params *Params) map[string]string {
+	t.Helper()
+	files, err := generate(t, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// checkGo type-checks the Go files as a single package, ex. classes defined twice or never fail the check
+func checkGo(t *testing.T, files map[string]string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for name, body := range files {
+		f, err := parser.ParseFile(fset, name, body, 0)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, body)
+		}
+		parsed = append(parsed, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("models", fset, parsed, nil); err != nil {
+		for name, body := range files {
+			t.Logf("%s:\n%s", name, body)
+		}
+		t.Fatal(err)
+	}
+}
+
+func TestGenGoCompiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *Params
+	}{
+		{"plain", &Params{}},
+		{"discriminators", &Params{Discriminators: []string{"type"}}},
+		{"dedupe", &Params{Discriminators: []string{"type"}, DeduplicateClasses: "first", ClassNameCollision: "prefix"}},
+		{"flatten", &Params{Flatten: &Flatten{}}},
+		{"flatten depth", &Params{Flatten: &Flatten{Depth: 1}}},
+		{"inline", &Params{InlineObjects: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Data = testFile(t, "sample.json")
+			tt.params.Templates = []*File{testFile(t, "models.go.tmpl")}
+			if tt.params.ClassNameCollision == "" {
+				// geo of the address and of the billing are different classes of the same name
+				tt.params.ClassNameCollision = "suffix"
+			}
+			checkGo(t, mustGenerate(t, tt.params))
+		})
+	}
+}
+
+// TestGenGolden renders the template of the README example, the data keys are kept in the json tags
+func TestGenGolden(t *testing.T) {
+	tmpl := testFile(t, "template.txt")
+	tmpl.Name = "rootClass.go.tmpl"
+	files := mustGenerate(t, &Params{
+		RootClassName: "RootClass",
+		Data:          testFile(t, "data.json"),
+		Templates:     []*File{tmpl},
+	})
+	want, err := io.ReadAll(testFile(t, "rootClass.go.txt").Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files["rootClass.go"]; got != string(want) {
+		t.Errorf("rootClass.go:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenMultiRoot(t *testing.T) {
+	files := mustGenerate(t, &Params{
+		Data:      testFile(t, "multi.json"),
+		Templates: []*File{testFile(t, "models.go.tmpl")},
+		MultiRoot: true,
+	})
+	tests := []struct {
+		file  string
+		class string
+	}{
+		{"user_models.go", "type User struct"},
+		{"order_models.go", "type Order struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body, ok := files[tt.file]
+			if !ok {
+				t.Fatalf("no %s in %v", tt.file, files)
+			}
+			if !strings.Contains(body, tt.class) {
+				t.Errorf("no %q in\n%s", tt.class, body)
+			}
+		})
+	}
+
+	// the shared address is defined once and the colliding meta classes are prefixed
+	checkGo(t, files)
+	var all string
+	for _, body := range files {
+		all += body
+	}
+	for _, class := range []string{"type Address struct", "type Meta struct", "type OrderMeta struct"} {
+		if strings.Count(all, class) != 1 {
+			t.Errorf("%q is defined %d times", class, strings.Count(all, class))
+		}
+	}
+}
+
+// TestGenMultiRootParts checks that the dart part directives name the files of their roots
+func TestGenMultiRootParts(t *testing.T) {
+	files := mustGenerate(t, &Params{
+		Data:      testFile(t, "multi.json"),
+		Templates: packTemplates(t, "dart/json_serializable"),
+		MultiRoot: true,
+	})
+	for file, want := range map[string]string{
+		"user_models.dart":  "part 'user_models.g.dart';",
+		"order_models.dart": "part 'order_models.g.dart';",
+	} {
+		body, ok := files[file]
+		if !ok {
+			t.Fatalf("no %s in %v", file, files)
+		}
+		if !strings.Contains(body, want) {
+			t.Errorf("no %q in\n%s", want, body)
+		}
+	}
+}
+
+func TestGenMultiRootOutputs(t *testing.T) {
+	files := mustGenerate(t, &Params{
+		Data:      testFile(t, "multi.json"),
+		Outputs:   []string{"jsonschema"},
+		MultiRoot: true,
+	})
+	body, ok := files["schema.json"]
+	if !ok {
+		t.Fatalf("no schema.json in %v", files)
+	}
+	doc := struct {
+		Defs map[string]interface{} `json:"$defs"`
+	}{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, class := range []string{"User", "Order", "Address", "Geo"} {
+		if _, ok := doc.Defs[class]; !ok {
+			t.Errorf("no definition of %s in\n%s", class, body)
+		}
+	}
+}
+
+func TestGenOutputsValidate(t *testing.T) {
+	params := &Params{
+		Data:           testFile(t, "sample.json"),
+		Outputs:        Outputs(),
+		Discriminators: []string{"type"},
+	}
+	files := mustGenerate(t, params)
+	validate := map[string]func(body string) error{
+		"schema.json": func(body string) error {
+			return json.Unmarshal([]byte(body), &map[string]interface{}{})
+		},
+		"openapi.yaml": func(body string) error {
+			return yaml.Unmarshal([]byte(body), &map[string]interface{}{})
+		},
+		"schema.proto":   balanced,
+		"schema.graphql": balanced,
+		"schema.sql":     balanced,
+	}
+	for name, check := range validate {
+		t.Run(name, func(t *testing.T) {
+			body, ok := files[name]
+			if !ok {
+				t.Fatalf("no %s", name)
+			}
+			if err := check(body); err != nil {
+				t.Errorf("%v\n%s", err, body)
+			}
+		})
+	}
+}
+
+func TestGenSQLKeys(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    []string
+	}{
+		{"postgres", []string{"id text PRIMARY KEY", "child_id text NOT NULL", "name text NOT NULL"}},
+		// text columns can't be keys in MySQL
+		{"mysql", []string{"id varchar(255) PRIMARY KEY", "child_id varchar(255) NOT NULL", "name text NOT NULL"}},
+		{"sqlite", []string{"id TEXT PRIMARY KEY", "child_id TEXT NOT NULL"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			files := mustGenerate(t, &Params{
+				Data:    stringFile("data.json", `{"id": "a", "name": "b", "child": {"id": "c"}}`),
+				Outputs: []string{"sql"},
+				SQL:     &SQL{Dialect: tt.dialect},
+			})
+			for _, want := range tt.want {
+				if !strings.Contains(files["schema.sql"], want) {
+					t.Errorf("no %q in\n%s", want, files["schema.sql"])
+				}
+			}
+		})
+	}
+}
+
+func TestGenSQLFlatten(t *testing.T) {
+	files := mustGenerate(t, &Params{
+		Data:    stringFile("data.json", `{"id": 1, "user": {"id": 2, "geo": {"lat": 1.5}}}`),
+		Outputs: []string{"sql"},
+		Flatten: &Flatten{},
+	})
+	want := "id bigint PRIMARY KEY,\n  user_id bigint NOT NULL,\n  user_geo_lat double precision NOT NULL\n"
+	if !strings.Contains(files["schema.sql"], want) {
+		t.Errorf("no %q in\n%s", want, files["schema.sql"])
+	}
+}
+
+func packTemplates(t *testing.T, pack string) []*File {
+	t.Helper()
+	packTemplates, err := templates.Pack(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make([]*File, 0, len(packTemplates))
+	for _, tmpl := range packTemplates {
+		files = append(files, &File{Name: tmpl.Name, Body: bytes.NewBuffer(tmpl.Body)})
+	}
+	return files
+}
+
+func TestGenPacks(t *testing.T) {
+	for _, pack := range templates.Packs() {
+		t.Run(pack, func(t *testing.T) {
+			params := &Params{Data: testFile(t, "sample.json"), Discriminators: []string{"type"}, Package: "com.example"}
+			params.Templates = packTemplates(t, pack)
+			for name, body := range mustGenerate(t, params) {
+				if err := balanced(body); err != nil {
+					t.Errorf("%s: %v\n%s", name, err, body)
+				}
+			}
+		})
+	}
+}
+
+// packData has a nullable, optional, date-time and array properties
+const packData = `[{"id": 1, "name": "a", "tags": ["x"], "at": "2024-05-06T07:08:09Z"}, {"id": 2, "name": null}]`
+
+func TestGenPackOutput(t *testing.T) {
+	tests := []struct {
+		pack string
+		file string
+		want []string
+	}{
+		{"ts/interface", "models.ts", []string{"export interface Order {", "name: string | null;", "tags?: string[];"}},
+		{"ts/zod", "schemas.zod.ts", []string{
+			"export const OrderSchema = z.object({", "name: z.string().nullable(),",
+			"tags: z.array(z.string()).optional(),", "export type Order = z.infer<typeof OrderSchema>;",
+		}},
+		{"py/dataclass", "models.py", []string{
+			"@dataclass\nclass Order:", "name: Optional[str]\n", "tags: Optional[list[str]] = None", "import datetime\n",
+		}},
+		{"py/pydantic", "models.py", []string{"class Order(BaseModel):", "at: Optional[datetime.datetime] = None"}},
+		{"java/pojo", "com/example/Order.java", []string{
+			"package com.example;", "import java.util.List;", "public class Order {", "private String name;",
+			"public void setTags(List<String> tags) {",
+		}},
+		{"java/record", "com/example/Order.java", []string{"public record Order(", `@JsonProperty("at") OffsetDateTime at`}},
+		{"kt/data", "com/example/Order.kt", []string{"data class Order(", "val name: String?,", "val tags: List<String>? = null,"}},
+		{"cs/class", "Models.cs", []string{
+			"using System;\n", "public class Order\n", "public required string? Name { get; set; }", "public List<string>? Tags { get; set; }",
+		}},
+		{"cs/record", "Models.cs", []string{"using System;\n", "public record Order(", `[property: JsonPropertyName("at")] DateTime? At`}},
+		{"rs/serde", "models.rs", []string{
+			"pub struct Order {", "pub name: Option<String>,",
+			"#[serde(default, skip_serializing_if = \"Option::is_none\")]\n    pub tags: Option<Vec<String>>,",
+		}},
+		{"swift/codable", "Models.swift", []string{"struct Order: Codable {", "let name: String?", "let at: Date?"}},
+		{"dart/json_serializable", "models.dart", []string{
+			"part 'models.g.dart';", "class Order {", "required this.name,", "final List<String>? tags;",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pack, func(t *testing.T) {
+			files := mustGenerate(t, &Params{
+				RootClassName: "Order",
+				Package:       "com.example",
+				Data:          stringFile("order.json", packData),
+				Templates:     packTemplates(t, tt.pack),
+			})
+			body, ok := files[tt.file]
+			if !ok {
+				t.Fatalf("no %s in %v", tt.file, files)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("no %q in\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+// TestGenPackTypes checks the types of the packs which need more than packData
+func TestGenPackTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		pack string
+		data string
+		file string
+		want []string
+	}{
+		{
+			"big integers", "ts/interface", `{"id": 12345678901234567890, "ids": [12345678901234567890]}`, "models.ts",
+			[]string{"id: string;", "ids: string[];"},
+		},
+		{
+			"big integers", "ts/zod", `{"id": 12345678901234567890, "ids": [12345678901234567890]}`, "schemas.zod.ts",
+			[]string{"id: z.string(),", "ids: z.array(z.string()),"},
+		},
+		{
+			"big integers", "dart/json_serializable", `{"id": 12345678901234567890, "ids": [12345678901234567890]}`,
+			"models.dart", []string{"final String id;", "final List<String> ids;"},
+		},
+		{
+			"recursion", "ts/zod", `{"name": "a", "note": null, "parent": {"name": "b", "parent": {"name": "c"}}}`,
+			"schemas.zod.ts", []string{
+				"export interface Order {\n  name: string;\n  note?: unknown;\n  parent: Order;\n}",
+				"export const OrderSchema: z.ZodType<Order> = z.lazy(() => z.object({",
+				"parent: z.lazy(() => OrderSchema),",
+			},
+		},
+		{
+			"optional primitives", "java/record", `[{"id": 1, "ok": true, "score": 1.5}, {"id": 2}]`,
+			"com/example/Order.java", []string{"long id,", "Boolean ok,", "Double score\n"},
+		},
+		{
+			"recursion", "swift/codable", `{"name": "a", "parent": {"name": "b", "parent": {"name": "c"}}, "replies": []}`,
+			"Models.swift", []string{"final class Order: Codable {", "let parent: Order"},
+		},
+		{
+			"recursion in arrays", "swift/codable", `{"name": "a", "replies": [{"name": "b", "replies": [{"name": "c"}]}]}`,
+			"Models.swift", []string{"struct Order: Codable {", "let replies: [Order]"},
+		},
+		{
+			"Foundation names", "swift/codable", `{"data": {"x": 1}, "locale": {"code": "a"}}`,
+			"Models.swift", []string{"let data: Data_", "struct Data_: Codable {", "struct Locale_: Codable {"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pack+" "+tt.name, func(t *testing.T) {
+			files := mustGenerate(t, &Params{
+				RootClassName: "Order",
+				Package:       "com.example",
+				Data:          stringFile("order.json", tt.data),
+				Templates:     packTemplates(t, tt.pack),
+				// recursive classes
+				DetectRecursion: true,
+			})
+			body, ok := files[tt.file]
+			if !ok {
+				t.Fatalf("no %s in %v", tt.file, files)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("no %q in\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+// balanced checks the brackets of the code outside of string literals
+func balanced(body string) error {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	var stack []rune
+	var quote rune
+	for i, c := range body {
+		switch {
+		case quote != 0:
+			if c == quote && (i == 0 || body[i-1] != '\\') {
+				quote = 0
+			}
+		case c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, c)
+		case pairs[c] != 0:
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[c] {
+				return errors.Errorf("unbalanced %c at %d", c, i)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return errors.Errorf("unclosed %c", stack[len(stack)-1])
+	}
+	return nil
+}
+
+func TestGenOverrideTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		err       string
+	}{
+		{"built-in type", "$.id:\n  type: string\n", ""},
+		{"custom type", "$.id:\n  type: uuid\n", ""},
+		{"unknown type", "$.id:\n  type: guid\n", `"$.id": unknown type "guid"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(t, &Params{
+				Data:      testFile(t, "sample.json"),
+				Templates: []*File{testFile(t, "models.go.tmpl")},
+				Overrides: stringFile("overrides.yaml", tt.overrides),
+			})
+			if tt.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("error %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGenTypeMappingErrors(t *testing.T) {
+	langs, err := LoadLangSettings(stringFile("bad.yaml", `
+code: bad
+extends: go
+fileExtensions: [bad]
+configMapping:
+  typeMapping:
+    int: "{{ .Nope }}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tmpl := range []string{"{{ range .Properties }}{{ .Type }}{{ end }}", "{{ range .Properties }}{{ .Type.Doc }}{{ end }}"} {
+		t.Run(tmpl, func(t *testing.T) {
+			files, err := generate(t, &Params{
+				LangSettings: langs,
+				Data:         stringFile("data.json", `{"a": 1}`),
+				Templates:    []*File{stringFile("out.bad.tmpl", tmpl)},
+			})
+			if err == nil {
+				t.Errorf("no error, files %v", files)
+			}
+		})
+	}
+}
+
+func TestLoadLangSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		codes []string
+		err   string
+	}{
+		{
+			name: "extends predefined",
+			files: map[string]string{"go.yaml": `
+code: go
+extends: go
+configMapping:
+  typeMapping:
+    dateTime: civil.DateTime
+`},
+			codes: []string{"go"},
+		},
+		{
+			name: "list extending a loaded language",
+			files: map[string]string{"langs.yaml": `
+- code: base
+  extends: ts
+  fileExtensions: [base]
+- code: child
+  extends: base
+  fileExtensions: [child]
+`},
+			codes: []string{"base", "child"},
+		},
+		{
+			name:  "json",
+			files: map[string]string{"lang.json": `{"code": "js", "extends": "ts", "fileExtensions": ["js"]}`},
+			codes: []string{"js"},
+		},
+		{
+			name:  "unknown extends",
+			files: map[string]string{"a.yaml": "code: a\nextends: nope\nfileExtensions: [a]\n"},
+			err:   `unknown language "nope"`,
+		},
+		{
+			name:  "cycle",
+			files: map[string]string{"a.yaml": "- code: a\n  extends: b\n- code: b\n  extends: a\n"},
+			err:   "cycle",
+		},
+		{
+			name: "duplicate code",
+			files: map[string]string{
+				"a.yaml": "code: a\nextends: ts\nfileExtensions: [a]\n",
+				"b.yaml": "code: a\nextends: ts\nfileExtensions: [b]\n",
+			},
+			err: "already defined",
+		},
+		{
+			name:  "unknown key",
+			files: map[string]string{"a.yaml": "code: a\nextends: ts\nfileExtension: [a]\n"},
+			err:   "fileExtension",
+		},
+		{
+			name:  "no code",
+			files: map[string]string{"a.yaml": "extends: ts\n"},
+			err:   "code is required",
+		},
+		{
+			name:  "no extensions",
+			files: map[string]string{"a.yaml": "code: a\nconfigMapping:\n  typeMapping:\n    int: int\n"},
+			err:   "fileExtensions is required",
+		},
+		{
+			name:  "invalid extension",
+			files: map[string]string{"a.yaml": "code: a\nextends: ts\nfileExtensions: [.a]\n"},
+			err:   "invalid file extension",
+		},
+		{
+			name:  "invalid type template",
+			files: map[string]string{"a.yaml": "code: a\nextends: ts\nfileExtensions: [a]\nconfigMapping:\n  typeMapping:\n    int: \"{{ .Key \"\n"},
+			err:   "typeMapping: int",
+		},
+		{
+			name:  "invalid custom type template",
+			files: map[string]string{"a.yaml": "code: a\nextends: ts\nfileExtensions: [a]\nconfigMapping:\n  typeMapping:\n    custom:\n      uuid: \"{{ \"\n"},
+			err:   "custom.uuid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []*File
+			for name, body := range tt.files {
+				files = append(files, stringFile(name, body))
+			}
+			settings, err := LoadLangSettings(files...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var codes []string
+			for _, setting := range settings {
+				codes = append(codes, setting.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.codes, ",") {
+				t.Errorf("codes %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestLoadLangSettingsExtends(t *testing.T) {
+	settings, err := LoadLangSettings(stringFile("go.yaml", `
+code: go
+extends: go
+configMapping:
+  typeMapping:
+    dateTime: civil.DateTime
+    custom:
+      uuid: string
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping := settings[0].ConfigMapping.TypeMapping
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{meta.TypeDateTime, "civil.DateTime"},
+		{"uuid", "string"},
+		// the settings absent in the file are the settings of the extended language
+		{"int", "int"},
+		{"string", "string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if typ, err := mapping.GetType(tt.key); err != nil || typ != tt.want {
+				t.Errorf("type %s, %v, want %s", typ, err, tt.want)
+			}
+		})
+	}
+	if predefinedLang("go").ConfigMapping.TypeMapping.DateTime == "civil.DateTime" {
+		t.Error("the predefined language is changed")
+	}
+}
+
+func TestPredefinedLangSettingsValidate(t *testing.T) {
+	for _, setting := range PredefinedLangSettings {
+		t.Run(setting.Code, func(t *testing.T) {
+			if err := setting.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLayeredTypeFormatter(t *testing.T) {
+	doc := &TypeMapping{Int: "integer"}
+	types := &TypeMapping{Int: "int", String: "string", Nullable: "?{{ . }}"}
+	tests := []struct {
+		name string
+		typ  meta.Type
+		doc  string
+		str  string
+	}{
+		{"doc type", meta.Type{Value: meta.TypeInt}, "integer", "int"},
+		{"type", meta.Type{Value: meta.TypeString}, "string", "string"},
+		{"common type", meta.Type{Value: meta.TypeBool}, "bool", "bool"},
+		{"nullable of the mapping", meta.Type{Value: meta.TypeString, Nullable: true}, "?string", "?string"},
+		{"nullable of the doc mapping", meta.Type{Value: meta.TypeInt, Nullable: true}, "integer", "?int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.typ.Formatters = &meta.TypeFormatters{
+				Type: layeredTypeFormatter(types, commonTypeMapping()),
+				Doc:  layeredTypeFormatter(doc, types, commonTypeMapping()),
+			}
+			if s := tt.typ.String(); s != tt.str {
+				t.Errorf("type %s, want %s", s, tt.str)
+			}
+			if s, err := tt.typ.Doc(); err != nil || s != tt.doc {
+				t.Errorf("doc type %s, %v, want %s", s, err, tt.doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// IdentifierRules describes valid class and property names of a language
+type IdentifierRules struct {
+	// ReservedWords can't be used as class and property names
+	ReservedWords []string `json:"reservedWords" yaml:"reservedWords" xml:"ReservedWords"`
+	// ReservedClassNames can't be used as class names only
+	ReservedClassNames []string `json:"reservedClassNames" yaml:"reservedClassNames" xml:"ReservedClassNames"`
+	// Escape is a template of an escaped reserved word, "{{ . }}_" by default
+	Escape string `json:"escape" yaml:"escape" xml:"Escape"`
+	// DigitPrefix is prepended to names starting with a digit, "_" by default
+	DigitPrefix string `json:"digitPrefix" yaml:"digitPrefix" xml:"DigitPrefix"`
+	// CaseInsensitive compares names with reserved words ignoring case
+	CaseInsensitive bool `json:"caseInsensitive" yaml:"caseInsensitive" xml:"CaseInsensitive"`
+	// ASCII transliterates cyrillic letters and spells other non-ASCII letters and digits by their code points,
+	// ex. 日本 is u65e5_u672c
+	ASCII bool `json:"ascii" yaml:"ascii" xml:"ASCII"`
+}
+
+// Normalize replaces characters that can't be used in identifiers with the word separator
+func (r *IdentifierRules) Normalize(name string) string {
+	b := &strings.Builder{}
+	for _, c := range name {
+		if r.ASCII && c > unicode.MaxASCII {
+			if lat, ok := cyrillic[unicode.ToLower(c)]; ok {
+				if unicode.IsUpper(c) && lat != "" {
+					lat = strings.ToUpper(lat[:1]) + lat[1:]
+				}
+				b.WriteString(lat)
+			} else if unicode.IsLetter(c) || unicode.IsDigit(c) {
+				// a word of its own, so names of different letters stay different
+				fmt.Fprintf(b, "_u%x_", c)
+			} else {
+				b.WriteByte('_')
+			}
+			continue
+		}
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' {
+			b.WriteRune(c)
+		} else {
+			// word separator for the name formatting
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// Sanitize prefixes names starting with a digit and escapes reserved words
+func (r *IdentifierRules) Sanitize(name string, class bool) (string, error) {
+	if name == "" {
+		name = "_"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		prefix := r.DigitPrefix
+		if prefix == "" {
+			prefix = "_"
+		}
+		name = prefix + name
+	}
+
+	if !r.reserved(name, r.ReservedWords) && !(class && r.reserved(name, r.ReservedClassNames)) {
+		return name, nil
+	}
+
+	escape := r.Escape
+	if escape == "" {
+		escape = "{{ . }}_"
+	}
+	tmpl, err := template.New("").Parse(escape)
+	if err != nil {
+		return "", errors.WithMessage(err, "IdentifierRules.Escape template parse error")
+	}
+	b := &strings.Builder{}
+	if err := tmpl.Execute(b, name); err != nil {
+		return "", errors.WithMessage(err, "IdentifierRules.Escape template execute error")
+	}
+	return b.String(), nil
+}
+
+func (r *IdentifierRules) reserved(name string, words []string) bool {
+	for _, word := range words {
+		if word == name || r.CaseInsensitive && strings.EqualFold(word, name) {
+			return true
+		}
+	}
+	return false
+}
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+var goKeywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+	"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+	"switch", "type", "var",
+}
+
+var phpReservedClassNames = []string{
+	"abstract", "and", "array", "as", "bool", "break", "callable", "case", "catch", "class", "clone", "const",
+	"continue", "declare", "default", "do", "echo", "else", "elseif", "empty", "enddeclare", "endfor",
+	"endforeach", "endif", "endswitch", "endwhile", "enum", "eval", "exit", "extends", "false", "final",
+	"finally", "float", "fn", "for", "foreach", "function", "global", "goto", "if", "implements", "include",
+	"include_once", "instanceof", "insteadof", "int", "interface", "isset", "iterable", "list", "match",
+	"mixed", "namespace", "never", "new", "null", "object", "or", "parent", "print", "private", "protected",
+	"public", "readonly", "require", "require_once", "return", "self", "static", "string", "switch", "throw",
+	"trait", "true", "try", "unset", "use", "var", "void", "while", "xor", "yield",
+}
@@ -0,0 +1,60 @@
+package gen
+
+import "testing"
+
+func TestIdentifierRulesNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		ascii bool
+		want  string
+	}{
+		{"first-name", false, "first_name"},
+		{"a.b c", false, "a_b_c"},
+		{"имя", false, "имя"},
+		{"Имя", true, "Imya"},
+		{"щука", true, "shchuka"},
+		{"日本", true, "_u65e5__u672c_"},
+		{"a→b", true, "a_b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &IdentifierRules{ASCII: tt.ascii}
+			if got := r.Normalize(tt.name); got != tt.want {
+				t.Errorf("normalized %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifierRulesSanitize(t *testing.T) {
+	rules := &IdentifierRules{
+		ReservedWords:      []string{"type"},
+		ReservedClassNames: []string{"String"},
+		DigitPrefix:        "X",
+		CaseInsensitive:    true,
+	}
+	tests := []struct {
+		name  string
+		class bool
+		want  string
+	}{
+		{"name", false, "name"},
+		{"type", false, "type_"},
+		{"Type", true, "Type_"},
+		{"String", false, "String"},
+		{"String", true, "String_"},
+		{"1st", false, "X1st"},
+		{"", false, "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rules.Sanitize(tt.name, tt.class)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("sanitized %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package gen
+
+var csLangSettings = &LangSettings{
+	Code:               "cs",
+	Name:               "C#",
+	FileExtensions:     []string{"cs"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      csKeywords,
+		ReservedClassNames: csReservedClassNames,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:        "List<object>",
+			ArrayBool:    "List<bool>",
+			ArrayFloat:   "List<double>",
+			ArrayInt:     "List<long>",
+			ArrayBigInt:  "List<BigInteger>",
+			ArrayObject:  "List<{{ .Key }}>",
+			ArrayArray:   "List<{{ .Elem }}>",
+			ArrayString:  "List<string>",
+			Bool:         "bool",
+			Float:        "double",
+			Int:          "long",
+			BigInt:       "BigInteger",
+			Null:         "object",
+			Object:       "{{ .Key }}",
+			Map:          "Dictionary<string, {{ .Elem }}>",
+			String:       "string",
+			Time:         "TimeOnly",
+			Date:         "DateOnly",
+			DateTime:     "DateTime",
+			Duration:     "TimeSpan",
+			Nullable:     "{{ . }}?",
+			InlineObject: "Dictionary<string, object>",
+			Custom:       map[string]string{"uuid": "Guid"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.PascalCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var csKeywords = []string{
+	"abstract", "as", "base", "bool", "break", "byte", "case", "catch", "char", "checked", "class", "const",
+	"continue", "decimal", "default", "delegate", "do", "double", "else", "enum", "event", "explicit", "extern",
+	"false", "finally", "fixed", "float", "for", "foreach", "goto", "if", "implicit", "in", "int", "interface",
+	"internal", "is", "lock", "long", "namespace", "new", "null", "object", "operator", "out", "override",
+	"params", "private", "protected", "public", "readonly", "ref", "return", "sbyte", "sealed", "short",
+	"sizeof", "stackalloc", "static", "string", "struct", "switch", "this", "throw", "true", "try", "typeof",
+	"uint", "ulong", "unchecked", "unsafe", "ushort", "using", "virtual", "void", "volatile", "while",
+}
+
+// csReservedClassNames clash with the BCL types and the System.Text.Json attributes the packs use
+var csReservedClassNames = []string{
+	"BigInteger", "DateOnly", "DateTime", "Dictionary", "Guid", "JsonDerivedType", "JsonPolymorphic",
+	"JsonPropertyName", "List", "Object", "String", "TimeOnly", "TimeSpan",
+}
@@ -0,0 +1,61 @@
+package gen
+
+var dartLangSettings = &LangSettings{
+	Code:               "dart",
+	Name:               "Dart",
+	FileExtensions:     []string{"dart"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      dartKeywords,
+		ReservedClassNames: dartReservedClassNames,
+		// a leading _ makes a name private to its library
+		DigitPrefix: "x",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:       "List<dynamic>",
+			ArrayBool:   "List<bool>",
+			ArrayFloat:  "List<double>",
+			ArrayInt:    "List<int>",
+			ArrayBigInt: "List<String>",
+			ArrayObject: "List<{{ .Key }}>",
+			ArrayArray:  "List<{{ .Elem }}>",
+			ArrayString: "List<String>",
+			Bool:        "bool",
+			Float:       "double",
+			Int:         "int",
+			// web builds store int as a double, big integers stay exact as strings
+			BigInt:       "String",
+			Null:         "dynamic",
+			Object:       "{{ .Key }}",
+			Map:          "Map<String, {{ .Elem }}>",
+			String:       "String",
+			Time:         "String",
+			Date:         "DateTime",
+			DateTime:     "DateTime",
+			Duration:     "String",
+			Nullable:     "{{ . }}?",
+			InlineObject: "Map<String, dynamic>",
+			Custom:       map[string]string{"uuid": "String"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var dartKeywords = []string{
+	"abstract", "as", "assert", "async", "await", "break", "case", "catch", "class", "const", "continue",
+	"covariant", "default", "deferred", "do", "dynamic", "else", "enum", "export", "extends", "extension",
+	"external", "factory", "false", "final", "finally", "for", "get", "if", "implements", "import", "in",
+	"interface", "is", "late", "library", "mixin", "new", "null", "operator", "part", "required", "rethrow",
+	"return", "set", "static", "super", "switch", "this", "throw", "true", "try", "typedef", "var", "void",
+	"while", "with", "yield",
+}
+
+// dartReservedClassNames are dart:core types and the json_annotation names of the pack
+var dartReservedClassNames = []string{
+	"BigInt", "DateTime", "Duration", "JsonKey", "JsonSerializable", "List", "Map", "Object", "String",
+	"bool", "double", "int", "num",
+}
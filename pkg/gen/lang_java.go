@@ -0,0 +1,122 @@
+package gen
+
+var javaLangSettings = &LangSettings{
+	Code:               "java",
+	Name:               "Java",
+	FileExtensions:     []string{"java"},
+	SplitObjectByFiles: true,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      javaKeywords,
+		ReservedClassNames: javaReservedClassNames,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:       "List<Object>",
+			ArrayBool:   "List<Boolean>",
+			ArrayFloat:  "List<Double>",
+			ArrayInt:    "List<Long>",
+			ArrayBigInt: "List<BigInteger>",
+			ArrayObject: "List<{{ .Key }}>",
+			ArrayArray:  "List<{{ .Elem }}>",
+			ArrayString: "List<String>",
+			// primitives can't be null, so nullable and optional values are boxed
+			Bool:         "{{ if or .Nullable .Optional }}Boolean{{ else }}boolean{{ end }}",
+			Float:        "{{ if or .Nullable .Optional }}Double{{ else }}double{{ end }}",
+			Int:          "{{ if or .Nullable .Optional }}Long{{ else }}long{{ end }}",
+			BigInt:       "BigInteger",
+			Null:         "Object",
+			Object:       "{{ .Key }}",
+			Map:          "Map<String, " + javaBoxedElem + ">",
+			String:       "String",
+			Time:         "LocalTime",
+			Date:         "LocalDate",
+			DateTime:     "OffsetDateTime",
+			Duration:     "Duration",
+			InlineObject: "Map<String, Object>",
+			Custom:       map[string]string{"uuid": "UUID"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		FileNameMapping:     "{{ with packagePath }}{{ . }}/{{ end }}{{ .Key }}.java",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var ktLangSettings = &LangSettings{
+	Code:               "kt",
+	Name:               "Kotlin",
+	FileExtensions:     []string{"kt"},
+	SplitObjectByFiles: true,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      ktKeywords,
+		ReservedClassNames: ktReservedClassNames,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:       "List<JsonElement>",
+			ArrayBool:   "List<Boolean>",
+			ArrayFloat:  "List<Double>",
+			ArrayInt:    "List<Long>",
+			ArrayBigInt: "List<JsonPrimitive>",
+			ArrayObject: "List<{{ .Key }}>",
+			ArrayArray:  "List<{{ .Elem }}>",
+			ArrayString: "List<String>",
+			Bool:        "Boolean",
+			Float:       "Double",
+			Int:         "Long",
+			// kotlinx.serialization has no BigInteger serializer, a JsonPrimitive holds the number as written
+			BigInt:       "JsonPrimitive",
+			Null:         "JsonElement",
+			Object:       "{{ .Key }}",
+			Map:          "Map<String, {{ .Elem }}>",
+			String:       "String",
+			Time:         "LocalTime",
+			Date:         "LocalDate",
+			DateTime:     "Instant",
+			Duration:     "Duration",
+			Nullable:     "{{ . }}?",
+			InlineObject: "JsonObject",
+			Custom:       map[string]string{"uuid": "String"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		FileNameMapping:     "{{ with packagePath }}{{ . }}/{{ end }}{{ .Key }}.kt",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+// javaBoxedElem is the boxed element type of a map, ex. Long of Map<String, Long>
+const javaBoxedElem = "{{ if .Elem.IsInt }}Long{{ else if .Elem.IsFloat }}Double{{ else if .Elem.IsBool }}Boolean" +
+	"{{ else }}{{ .Elem }}{{ end }}"
+
+var javaKeywords = []string{
+	"abstract", "assert", "boolean", "break", "byte", "case", "catch", "char", "class", "const", "continue",
+	"default", "do", "double", "else", "enum", "extends", "false", "final", "finally", "float", "for", "goto",
+	"if", "implements", "import", "instanceof", "int", "interface", "long", "native", "new", "null", "package",
+	"private", "protected", "public", "record", "return", "short", "static", "strictfp", "super", "switch",
+	"synchronized", "this", "throw", "throws", "transient", "true", "try", "var", "void", "volatile", "while",
+	"yield",
+}
+
+// javaReservedClassNames would shadow java.lang or the Jackson and java.time imports of the packs
+var javaReservedClassNames = []string{
+	"BigInteger", "Boolean", "Class", "Double", "Duration", "Integer", "JsonProperty", "JsonSubTypes",
+	"JsonTypeInfo", "List", "LocalDate", "LocalTime", "Long", "Map", "Object", "OffsetDateTime", "Override",
+	"Record", "String", "UUID",
+}
+
+var ktKeywords = []string{
+	"as", "break", "class", "continue", "do", "else", "false", "for", "fun", "if", "in", "interface", "is",
+	"null", "object", "package", "return", "super", "this", "throw", "true", "try", "typealias", "typeof",
+	"val", "var", "when", "while",
+}
+
+// ktReservedClassNames would shadow kotlin builtins or the kotlinx.serialization imports
+var ktReservedClassNames = []string{
+	"Any", "Boolean", "Double", "Duration", "Instant", "Int", "JsonElement", "JsonObject", "JsonPrimitive",
+	"List", "LocalDate", "LocalTime", "Long", "Map", "Nothing", "SerialName", "Serializable", "String", "Unit",
+}
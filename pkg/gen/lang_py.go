@@ -0,0 +1,58 @@
+package gen
+
+var pyLangSettings = &LangSettings{
+	Code:               "py",
+	Name:               "Python",
+	FileExtensions:     []string{"py"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      pyKeywords,
+		ReservedClassNames: pyReservedClassNames,
+		// names starting with _ are private in dataclasses and pydantic models
+		DigitPrefix: "x",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:        "list[Any]",
+			ArrayBool:    "list[bool]",
+			ArrayFloat:   "list[float]",
+			ArrayInt:     "list[int]",
+			ArrayBigInt:  "list[int]",
+			ArrayObject:  "list[{{ .Key }}]",
+			ArrayArray:   "list[{{ .Elem }}]",
+			ArrayString:  "list[str]",
+			Bool:         "bool",
+			Float:        "float",
+			Int:          "int",
+			BigInt:       "int",
+			Null:         "Any",
+			Object:       "{{ .Key }}",
+			Map:          "dict[str, {{ .Elem }}]",
+			String:       "str",
+			Time:         "datetime.time",
+			Date:         "datetime.date",
+			DateTime:     "datetime.datetime",
+			Duration:     "datetime.timedelta",
+			Nullable:     "Optional[{{ . }}]",
+			InlineObject: "dict[str, Any]",
+			Custom:       map[string]string{"uuid": "uuid.UUID"},
+		},
+		TypeDocMapping:   nil,
+		ClassNameMapping: "{{ .Key.PascalCase }}",
+		// templates alias the properties to their original keys, ex. first_name = Field(alias="first name")
+		PropertyNameMapping: "{{ .Key.SnakeCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var pyKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue", "def",
+	"del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda",
+	"nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield",
+}
+
+// pyReservedClassNames would shadow builtins and the typing, dataclasses and pydantic imports
+var pyReservedClassNames = []string{
+	"Annotated", "Any", "BaseModel", "ConfigDict", "Field", "Literal", "Optional", "Union", "bool", "dataclass",
+	"datetime", "dict", "field", "float", "int", "list", "object", "str", "type", "uuid",
+}
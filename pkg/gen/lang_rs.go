@@ -0,0 +1,61 @@
+package gen
+
+var rsLangSettings = &LangSettings{
+	Code:               "rs",
+	Name:               "Rust",
+	FileExtensions:     []string{"rs"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      rsKeywords,
+		ReservedClassNames: rsReservedClassNames,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:       "Vec<serde_json::Value>",
+			ArrayBool:   "Vec<bool>",
+			ArrayFloat:  "Vec<f64>",
+			ArrayInt:    "Vec<i64>",
+			ArrayBigInt: "Vec<serde_json::Number>",
+			ArrayObject: "Vec<{{ .Key }}>",
+			ArrayArray:  "Vec<{{ .Elem }}>",
+			ArrayString: "Vec<String>",
+			Bool:        "bool",
+			Float:       "f64",
+			Int:         "i64",
+			// exact with the arbitrary_precision feature of serde_json
+			BigInt: "serde_json::Number",
+			Null:   "serde_json::Value",
+			// recursive structs must be boxed to have a size
+			Object: "{{ if .Recursive }}Box<{{ .Key }}>{{ else }}{{ .Key }}{{ end }}",
+			Map:    "HashMap<String, {{ .Elem }}>",
+			String: "String",
+			Time:   "NaiveTime",
+			Date:   "NaiveDate",
+			// chrono durations have no serde support
+			DateTime:     "DateTime<FixedOffset>",
+			Duration:     "String",
+			Nullable:     "Option<{{ . }}>",
+			InlineObject: "serde_json::Value",
+			Custom:       map[string]string{"uuid": "Uuid"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.SnakeCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var rsKeywords = []string{
+	"Self", "abstract", "as", "async", "await", "become", "box", "break", "const", "continue", "crate", "do",
+	"dyn", "else", "enum", "extern", "false", "final", "fn", "for", "if", "impl", "in", "let", "loop", "macro",
+	"match", "mod", "move", "mut", "override", "priv", "pub", "ref", "return", "self", "static", "struct",
+	"super", "trait", "true", "try", "type", "typeof", "unsafe", "unsized", "use", "virtual", "where", "while",
+	"yield",
+}
+
+// rsReservedClassNames would shadow the prelude and the serde, chrono and uuid imports
+var rsReservedClassNames = []string{
+	"Box", "DateTime", "Deserialize", "FixedOffset", "HashMap", "NaiveDate", "NaiveTime", "Option", "Result",
+	"Serialize", "String", "Uuid", "Vec",
+}
@@ -0,0 +1,62 @@
+package gen
+
+var swiftLangSettings = &LangSettings{
+	Code:               "swift",
+	Name:               "Swift",
+	FileExtensions:     []string{"swift"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedWords:      swiftKeywords,
+		ReservedClassNames: swiftReservedClassNames,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:       "[AnyCodable]",
+			ArrayBool:   "[Bool]",
+			ArrayFloat:  "[Double]",
+			ArrayInt:    "[Int]",
+			ArrayBigInt: "[Decimal]",
+			ArrayObject: "[{{ .Key }}]",
+			ArrayArray:  "[{{ .Elem }}]",
+			ArrayString: "[String]",
+			Bool:        "Bool",
+			Float:       "Double",
+			Int:         "Int",
+			BigInt:      "Decimal",
+			// values of unknown types need the AnyCodable package
+			Null:   "AnyCodable",
+			Object: "{{ .Key }}",
+			Map:    "[String: {{ .Elem }}]",
+			String: "String",
+			// dates without time and times without date have no decoding strategy of JSONDecoder
+			Time:         "String",
+			Date:         "String",
+			DateTime:     "Date",
+			Duration:     "String",
+			Nullable:     "{{ . }}?",
+			InlineObject: "[String: AnyCodable]",
+			Custom:       map[string]string{"uuid": "UUID"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+var swiftKeywords = []string{
+	"Any", "Self", "Type", "as", "associatedtype", "break", "case", "catch", "class", "continue", "default",
+	"defer", "deinit", "do", "else", "enum", "extension", "fallthrough", "false", "fileprivate", "for", "func",
+	"guard", "if", "import", "in", "init", "inout", "internal", "is", "let", "nil", "open", "operator",
+	"private", "protocol", "public", "repeat", "rethrows", "return", "self", "static", "struct", "subscript",
+	"super", "switch", "throw", "throws", "true", "try", "typealias", "var", "where", "while",
+}
+
+// swiftReservedClassNames would hide standard and Foundation types, ex. a Data class hides Foundation.Data
+var swiftReservedClassNames = []string{
+	"AnyCodable", "Array", "Bool", "Calendar", "Character", "Codable", "CodingKey", "CodingKeys", "Data", "Date",
+	"Decimal", "Decoder", "Dictionary", "Double", "Encoder", "Error", "Float", "Int", "Int64", "JSONDecoder",
+	"JSONEncoder", "Locale", "Measurement", "Notification", "Optional", "Result", "Set", "String", "TimeZone",
+	"URL", "URLComponents", "UUID",
+}
@@ -0,0 +1,125 @@
+package gen
+
+var tsLangSettings = &LangSettings{
+	Code:               "ts",
+	Name:               "TypeScript",
+	FileExtensions:     []string{"ts"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		// property names are data keys, quoted by templates if needed, so only class names are checked
+		ReservedClassNames: tsReservedWords,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		// big integers lose precision as numbers, they are decimal strings
+		TypeMapping: &TypeMapping{
+			Array:        "unknown[]",
+			ArrayBool:    "boolean[]",
+			ArrayFloat:   "number[]",
+			ArrayInt:     "number[]",
+			ArrayBigInt:  "string[]",
+			ArrayObject:  "{{ .Key }}[]",
+			ArrayArray:   "{{ .Elem }}[]",
+			ArrayString:  "string[]",
+			Bool:         "boolean",
+			Float:        "number",
+			Int:          "number",
+			BigInt:       "string",
+			Null:         "unknown",
+			Object:       "{{ .Key }}",
+			Map:          "Record<string, {{ .Elem }}>",
+			String:       "string",
+			Time:         "string",
+			Date:         "string",
+			DateTime:     "string",
+			Duration:     "string",
+			Nullable:     "{{ . }} | null",
+			InlineObject: "{ {{ range .Inline.Properties }}{{ quoteKey .OriginalKey }}: {{ .Type }}; {{ end }}}",
+			Custom:       map[string]string{"uuid": "string"},
+		},
+		TypeDocMapping:      nil,
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+// zodLangSettings are zod schemas of TypeScript, ex. schemas.zod.ts
+var zodLangSettings = &LangSettings{
+	Code:               "zod",
+	Name:               "TypeScript Zod",
+	FileExtensions:     []string{"zod"},
+	SplitObjectByFiles: false,
+	Identifiers: &IdentifierRules{
+		ReservedClassNames: tsReservedWords,
+		DigitPrefix:        "_",
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping: &TypeMapping{
+			Array:        "z.array(z.unknown())",
+			ArrayBool:    "z.array(z.boolean())",
+			ArrayFloat:   "z.array(z.number())",
+			ArrayInt:     "z.array(z.number().int())",
+			ArrayBigInt:  "z.array(z.string())",
+			ArrayObject:  "z.array(" + zodSchemaRef + ")",
+			ArrayArray:   "z.array({{ .Elem }})",
+			ArrayString:  "z.array(z.string())",
+			Bool:         "z.boolean()",
+			Float:        "z.number()",
+			Int:          "z.number().int()",
+			BigInt:       "z.string()",
+			Null:         "z.unknown()",
+			Object:       zodSchemaRef,
+			Map:          "z.record({{ .Elem }})",
+			String:       "z.string()",
+			Time:         "z.string()",
+			Date:         "z.string()",
+			DateTime:     "z.string().datetime({ offset: true })",
+			Duration:     "z.string()",
+			Nullable:     "{{ . }}.nullable()",
+			InlineObject: "z.object({ {{ range .Inline.Properties }}{{ quoteKey .OriginalKey }}: {{ .Type }}, {{ end }}})",
+			Custom:       map[string]string{"uuid": "z.string().uuid()"},
+		},
+		// the TypeScript types of the interfaces declared for recursive classes, which zod can't infer
+		TypeDocMapping: &TypeMapping{
+			Array:        "unknown[]",
+			ArrayBool:    "boolean[]",
+			ArrayFloat:   "number[]",
+			ArrayInt:     "number[]",
+			ArrayBigInt:  "string[]",
+			ArrayObject:  "{{ .Key }}[]",
+			ArrayArray:   "{{ .Elem.Doc }}[]",
+			ArrayString:  "string[]",
+			Bool:         "boolean",
+			Float:        "number",
+			Int:          "number",
+			BigInt:       "string",
+			Null:         "unknown",
+			Object:       "{{ .Key }}",
+			Map:          "Record<string, {{ .Elem.Doc }}>",
+			String:       "string",
+			Time:         "string",
+			Date:         "string",
+			DateTime:     "string",
+			Duration:     "string",
+			Nullable:     "{{ . }} | null",
+			InlineObject: "{ {{ range .Inline.Properties }}{{ quoteKey .OriginalKey }}{{ if .Type.IsNull }}?{{ end }}: {{ .Type.Doc }}; {{ end }}}",
+			Custom:       map[string]string{"uuid": "string"},
+		},
+		ClassNameMapping:    "{{ .Key.PascalCase }}",
+		PropertyNameMapping: "{{ .Key.CamelCase }}",
+		SingularClassNames:  true,
+	},
+}
+
+// zodSchemaRef refers to the schema of the class, lazily if the class is recursive
+const zodSchemaRef = "{{ if .Recursive }}z.lazy(() => {{ .Key }}Schema){{ else }}{{ .Key }}Schema{{ end }}"
+
+var tsReservedWords = []string{
+	"any", "as", "boolean", "break", "case", "catch", "class", "const", "continue", "debugger", "default",
+	"delete", "do", "else", "enum", "export", "extends", "false", "finally", "for", "function", "if",
+	"implements", "import", "in", "instanceof", "interface", "let", "never", "new", "null", "number",
+	"object", "package", "private", "protected", "public", "return", "static", "string", "super", "switch",
+	"symbol", "this", "throw", "true", "try", "type", "typeof", "undefined", "unknown", "var", "void",
+	"while", "with", "yield", "Array", "Date", "Error", "Map", "Object", "Promise", "Record", "Set", "String",
+}
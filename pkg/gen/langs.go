@@ -0,0 +1,279 @@
+package gen
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// langFileExts are the extensions of language settings files read from directories
+var langFileExts = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// langNode is a language of a settings file before the resolution of extends
+type langNode struct {
+	file     string
+	node     *yaml.Node
+	code     string
+	extends  string
+	resolved *LangSettings
+	// resolving detects cycles of extends
+	resolving bool
+}
+
+// ReadLangSettings reads language settings files and the files of directories, see LoadLangSettings
+func ReadLangSettings(paths ...string) ([]*LangSettings, error) {
+	var files []*File
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.WithMessage(err, "can't read language settings")
+		}
+		names := []string{path}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, errors.WithMessage(err, "can't read language settings")
+			}
+			names = names[:0]
+			for _, entry := range entries {
+				if !entry.IsDir() && langFileExts[filepath.Ext(entry.Name())] {
+					names = append(names, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		for _, name := range names {
+			bs, err := os.ReadFile(name)
+			if err != nil {
+				return nil, errors.WithMessage(err, "can't read language settings")
+			}
+			files = append(files, &File{Name: name, Body: bytes.NewBuffer(bs)})
+		}
+	}
+	return LoadLangSettings(files...)
+}
+
+// LoadLangSettings loads languages from YAML or JSON files of a language or of a list of languages.
+// A language may extend a predefined or a loaded language, it overrides only the settings it has, ex.
+//
+//	code: go
+//	extends: go
+//	configMapping:
+//	  typeMapping:
+//	    dateTime: civil.DateTime
+//	    custom:
+//	      uuid: string
+//
+// The languages are validated, see LangSettings.Validate
+func LoadLangSettings(files ...*File) ([]*LangSettings, error) {
+	var nodes []*langNode
+	for _, file := range files {
+		fileNodes, err := loadLangNodes(file)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "can't load language settings \"%s\"", file.Name)
+		}
+		nodes = append(nodes, fileNodes...)
+	}
+
+	codes := map[string]*langNode{}
+	for _, n := range nodes {
+		if other, ok := codes[n.code]; ok {
+			return nil, errors.Errorf("language \"%s\" of \"%s\" is already defined in \"%s\"", n.code, n.file, other.file)
+		}
+		codes[n.code] = n
+	}
+
+	settings := make([]*LangSettings, 0, len(nodes))
+	for _, n := range nodes {
+		setting, err := resolveLang(n, codes)
+		if err != nil {
+			return nil, err
+		}
+		if err := setting.Validate(); err != nil {
+			return nil, errors.WithMessagef(err, "invalid language settings \"%s\"", n.file)
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+func loadLangNodes(file *File) ([]*langNode, error) {
+	bs, err := io.ReadAll(file.Body)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(bs, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	items := []*yaml.Node{root}
+	// decoding of the whole file reports unknown keys with their lines
+	dec := yaml.NewDecoder(bytes.NewReader(bs))
+	dec.KnownFields(true)
+	switch root.Kind {
+	case yaml.MappingNode:
+		err = dec.Decode(&LangSettings{})
+	case yaml.SequenceNode:
+		items = root.Content
+		err = dec.Decode(&[]*LangSettings{})
+	default:
+		return nil, errors.Errorf("line %d: language settings must be a mapping or a list of mappings", root.Line)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*langNode, 0, len(items))
+	for _, item := range items {
+		head := &struct {
+			Code    string `yaml:"code"`
+			Extends string `yaml:"extends"`
+		}{}
+		if err := item.Decode(head); err != nil {
+			return nil, err
+		}
+		if head.Code == "" {
+			return nil, errors.Errorf("line %d: code is required", item.Line)
+		}
+		nodes = append(nodes, &langNode{file: file.Name, node: item, code: head.Code, extends: head.Extends})
+	}
+	return nodes, nil
+}
+
+// resolveLang decodes the language over a copy of the language it extends
+func resolveLang(n *langNode, codes map[string]*langNode) (*LangSettings, error) {
+	if n.resolved != nil {
+		return n.resolved, nil
+	}
+	if n.resolving {
+		return nil, errors.Errorf("language \"%s\" of \"%s\" has a cycle of extends", n.code, n.file)
+	}
+	n.resolving = true
+	defer func() { n.resolving = false }()
+
+	setting := &LangSettings{}
+	if n.extends != "" {
+		var parent *LangSettings
+		// a language extending its own code extends the predefined language, ex. code: go, extends: go
+		if other, ok := codes[n.extends]; ok && other != n {
+			var err error
+			if parent, err = resolveLang(other, codes); err != nil {
+				return nil, err
+			}
+		} else {
+			parent = predefinedLang(n.extends)
+		}
+		if parent == nil {
+			return nil, errors.Errorf("language \"%s\" of \"%s\" extends unknown language \"%s\"", n.code, n.file, n.extends)
+		}
+		var err error
+		if setting, err = copyLang(parent); err != nil {
+			return nil, errors.WithMessagef(err, "can't extend language \"%s\"", n.extends)
+		}
+	}
+	if err := n.node.Decode(setting); err != nil {
+		return nil, errors.WithMessagef(err, "can't decode language \"%s\" of \"%s\"", n.code, n.file)
+	}
+	n.resolved = setting
+	return setting, nil
+}
+
+func predefinedLang(code string) *LangSettings {
+	for _, setting := range PredefinedLangSettings {
+		if setting.Code == code {
+			return setting
+		}
+	}
+	return nil
+}
+
+// copyLang deep copies the language, decoding over the copy keeps the settings absent in the document
+func copyLang(setting *LangSettings) (*LangSettings, error) {
+	bs, err := yaml.Marshal(setting)
+	if err != nil {
+		return nil, err
+	}
+	c := &LangSettings{}
+	if err := yaml.Unmarshal(bs, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the required settings and the syntax of the mapping templates
+func (s *LangSettings) Validate() error {
+	if s.Code == "" {
+		return errors.New("language code is required")
+	}
+	if len(s.FileExtensions) == 0 {
+		return errors.Errorf("language \"%s\": fileExtensions is required", s.Code)
+	}
+	for _, ext := range s.FileExtensions {
+		if ext == "" || strings.HasPrefix(ext, ".") {
+			return errors.Errorf("language \"%s\": invalid file extension \"%s\", ex. ts", s.Code, ext)
+		}
+	}
+	if s.ConfigMapping == nil {
+		return errors.Errorf("language \"%s\": configMapping is required", s.Code)
+	}
+	if s.ConfigMapping.TypeMapping == nil {
+		return errors.Errorf("language \"%s\": configMapping.typeMapping is required", s.Code)
+	}
+
+	tmpls := [][2]string{
+		{"configMapping.classNameMapping", s.ConfigMapping.ClassNameMapping},
+		{"configMapping.propertyNameMapping", s.ConfigMapping.PropertyNameMapping},
+	}
+	if s.Identifiers != nil {
+		tmpls = append(tmpls, [2]string{"identifiers.escape", s.Identifiers.Escape})
+	}
+	for _, tmpl := range tmpls {
+		if _, err := template.New(tmpl[0]).Parse(tmpl[1]); err != nil {
+			return errors.WithMessagef(err, "language \"%s\": invalid %s", s.Code, tmpl[0])
+		}
+	}
+	if _, err := template.New("").Funcs(renderFuncs("", nil)).Parse(s.ConfigMapping.FileNameMapping); err != nil {
+		return errors.WithMessagef(err, "language \"%s\": invalid configMapping.fileNameMapping", s.Code)
+	}
+	if err := s.ConfigMapping.TypeMapping.validate(); err != nil {
+		return errors.WithMessagef(err, "language \"%s\": invalid configMapping.typeMapping", s.Code)
+	}
+	if s.ConfigMapping.TypeDocMapping != nil {
+		if err := s.ConfigMapping.TypeDocMapping.validate(); err != nil {
+			return errors.WithMessagef(err, "language \"%s\": invalid configMapping.typeDocMapping", s.Code)
+		}
+	}
+	return nil
+}
+
+// validate parses the templates of the types, the errors are named by the yaml keys
+func (m *TypeMapping) validate() error {
+	v := reflect.ValueOf(m).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		typ, ok := v.Field(i).Interface().(string)
+		if !ok {
+			continue
+		}
+		name := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if _, err := template.New(name).Funcs(templateFuncs).Parse(typ); err != nil {
+			return errors.WithMessage(err, name)
+		}
+	}
+	for key, typ := range m.Custom {
+		if _, err := template.New(key).Funcs(templateFuncs).Parse(typ); err != nil {
+			return errors.WithMessagef(err, "custom.%s", key)
+		}
+	}
+	return nil
+}
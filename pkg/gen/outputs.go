@@ -0,0 +1,97 @@
+package gen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/schema"
+	"github.com/pkg/errors"
+)
+
+// output is a built-in schema rendered without templates, its lang names classes and properties
+type output struct {
+	fileName string
+	lang     *LangSettings
+	render   func(m *meta.Meta, params *Params) ([]byte, error)
+}
+
+var outputs = map[string]*output{
+	"jsonschema": {
+		fileName: "schema.json",
+		lang:     schemaLang(true, ""),
+		render: func(m *meta.Meta, _ *Params) ([]byte, error) {
+			return schema.JSONSchema(m)
+		},
+	},
+	"openapi": {
+		fileName: "openapi.yaml",
+		lang:     schemaLang(true, ""),
+		render: func(m *meta.Meta, _ *Params) ([]byte, error) {
+			return schema.OpenAPI(m)
+		},
+	},
+	"proto": {
+		fileName: "schema.proto",
+		lang:     schemaLang(false, "{{ .Key.SnakeCase }}"),
+		render: func(m *meta.Meta, params *Params) ([]byte, error) {
+			return schema.Proto(m, params.Package)
+		},
+	},
+	"graphql": {
+		fileName: "schema.graphql",
+		lang:     schemaLang(false, "{{ .Key.CamelCase }}"),
+		render: func(m *meta.Meta, _ *Params) ([]byte, error) {
+			return schema.GraphQL(m)
+		},
+	},
+	"sql": {
+		fileName: "schema.sql",
+		lang:     sqlLang,
+		render: func(m *meta.Meta, params *Params) ([]byte, error) {
+			options := params.SQL
+			if options == nil {
+				options = &SQL{}
+			}
+			dialect, ok := sqlDialects[options.Dialect]
+			if options.Dialect == "" {
+				dialect, ok = sqlDialects["postgres"], true
+			}
+			if !ok {
+				return nil, errors.Errorf("unknown SQL dialect \"%s\", available dialects: %s",
+					options.Dialect, strings.Join(SQLDialects(), ", "))
+			}
+			return schema.SQL(m, dialect.schemaDialect(), options.JSONObjects)
+		},
+	},
+}
+
+// Outputs returns the names of built-in outputs of Params.Outputs
+func Outputs() []string {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaLang names classes of schemas in PascalCase, JSON schemas keep the property keys of data
+func schemaLang(inlineObjects bool, propertyNameMapping string) *LangSettings {
+	typeMapping := &TypeMapping{}
+	if inlineObjects {
+		typeMapping.InlineObject = "object"
+	}
+	return &LangSettings{
+		Code: "schema",
+		Identifiers: &IdentifierRules{
+			ASCII: true,
+		},
+		ConfigMapping: &ConfigMapping{
+			TypeMapping:         typeMapping,
+			ClassNameMapping:    "{{ .Key.PascalCase }}",
+			PropertyNameMapping: propertyNameMapping,
+			SingularClassNames:  true,
+		},
+	}
+}
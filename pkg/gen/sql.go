@@ -0,0 +1,141 @@
+package gen
+
+import (
+	"sort"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/schema"
+)
+
+// SQL configures the sql output
+type SQL struct {
+	// Dialect is postgres, mysql or sqlite, see SQLDialects. Empty dialect is postgres
+	Dialect string `json:"dialect" xml:"Dialect" yaml:"dialect"`
+	// JSONObjects stores nested objects and arrays of objects in JSON columns instead of child tables
+	JSONObjects bool `json:"jsonObjects" xml:"JSONObjects" yaml:"jsonObjects"`
+}
+
+// sqlDialect is the DDL of a database, its TypeMapping maps scalars to column types
+type sqlDialect struct {
+	typeMapping *TypeMapping
+	// keyTypeMapping overrides TypeMapping for key columns
+	keyTypeMapping *TypeMapping
+	dialect        schema.SQLDialect
+}
+
+var sqlDialects = map[string]*sqlDialect{
+	"postgres": {
+		typeMapping: &TypeMapping{
+			Bool:     "boolean",
+			Float:    "double precision",
+			Int:      "bigint",
+			BigInt:   "numeric",
+			String:   "text",
+			Time:     "time",
+			Date:     "date",
+			DateTime: "timestamptz",
+			Duration: "interval",
+			Custom:   map[string]string{"uuid": "uuid"},
+		},
+		dialect: schema.SQLDialect{
+			Arrays:           true,
+			JSON:             "jsonb",
+			Serial:           "bigserial PRIMARY KEY",
+			SerialType:       "bigint",
+			Quote:            `"`,
+			AlterForeignKeys: true,
+		},
+	},
+	"mysql": {
+		typeMapping: &TypeMapping{
+			Bool:     "boolean",
+			Float:    "double",
+			Int:      "bigint",
+			BigInt:   "decimal(65)",
+			String:   "text",
+			Time:     "time",
+			Date:     "date",
+			DateTime: "datetime",
+			// ISO 8601 durations, ex. PT1H30M
+			Duration: "varchar(64)",
+			Custom:   map[string]string{"uuid": "char(36)"},
+		},
+		// text columns can't be keys without a prefix length
+		keyTypeMapping: &TypeMapping{
+			String: "varchar(255)",
+		},
+		dialect: schema.SQLDialect{
+			JSON:             "json",
+			Serial:           "bigint AUTO_INCREMENT PRIMARY KEY",
+			SerialType:       "bigint",
+			Quote:            "`",
+			AlterForeignKeys: true,
+		},
+	},
+	"sqlite": {
+		// type affinities of SQLite, dates and times are ISO 8601 texts
+		typeMapping: &TypeMapping{
+			Bool:     "INTEGER",
+			Float:    "REAL",
+			Int:      "INTEGER",
+			BigInt:   "TEXT",
+			String:   "TEXT",
+			Time:     "TEXT",
+			Date:     "TEXT",
+			DateTime: "TEXT",
+			Duration: "TEXT",
+		},
+		dialect: schema.SQLDialect{
+			JSON:       "TEXT",
+			Serial:     "INTEGER PRIMARY KEY AUTOINCREMENT",
+			SerialType: "INTEGER",
+			Quote:      `"`,
+		},
+	},
+}
+
+// SQLDialects returns the dialects of the sql output
+func SQLDialects() []string {
+	names := make([]string, 0, len(sqlDialects))
+	for name := range sqlDialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaDialect returns the dialect with the column types of its type mappings
+func (d *sqlDialect) schemaDialect() *schema.SQLDialect {
+	dialect := d.dialect
+	dialect.Type = d.columnType(d.typeMapping)
+	if d.keyTypeMapping != nil {
+		dialect.KeyType = d.columnType(d.keyTypeMapping, d.typeMapping)
+	}
+	return &dialect
+}
+
+// columnType formats column types by the first mapping with a non-empty type,
+// custom types without a mapping are strings
+func (d *sqlDialect) columnType(mappings ...*TypeMapping) meta.TypeFormatter {
+	format := layeredTypeFormatter(mappings...)
+	return func(t meta.Type) (string, error) {
+		if _, err := d.typeMapping.GetType(t.Value); err != nil {
+			t.Value = meta.TypeString
+		}
+		return format(t)
+	}
+}
+
+// sqlLang names tables and columns in snake_case
+var sqlLang = &LangSettings{
+	Code: "sql",
+	Identifiers: &IdentifierRules{
+		ASCII: true,
+	},
+	ConfigMapping: &ConfigMapping{
+		TypeMapping:         &TypeMapping{},
+		ClassNameMapping:    "{{ .Key.SnakeCase }}",
+		PropertyNameMapping: "{{ .Key.SnakeCase }}",
+		SingularClassNames:  true,
+	},
+}
@@ -17,37 +17,59 @@
 package meta
 
 import (
+	"encoding/json"
 	"math"
+	"math/big"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nikitaksv/strcase"
 
 	"github.com/araddon/dateparse"
 	"github.com/nikitaksv/dynjson"
+	"github.com/pkg/errors"
 )
 
 const (
 	TypeNull        = "null"
 	TypeInt         = "int"
+	TypeBigInt      = "bigInt"
 	TypeString      = "string"
 	TypeBool        = "bool"
 	TypeFloat       = "float"
 	TypeObject      = "object"
+	TypeMap         = "map"
 	TypeDate        = "date"
 	TypeTime        = "time"
 	TypeDateTime    = TypeDate + TypeTime
 	TypeDuration    = "duration"
 	TypeArray       = "array"
 	TypeArrayObject = "arrayObject"
+	TypeArrayArray  = "arrayArray"
 	TypeArrayInt    = "arrayInt"
+	TypeArrayBigInt = "arrayBigInt"
 	TypeArrayString = "arrayString"
 	TypeArrayBool   = "arrayBool"
 	TypeArrayFloat  = "arrayFloat"
 )
 
-type TypeFormatter func(t Type) string
+// builtinTypes are the type values of the parser, other values are custom types, ex. set by overrides
+var builtinTypes = map[string]bool{
+	TypeNull: true, TypeInt: true, TypeBigInt: true, TypeString: true, TypeBool: true, TypeFloat: true,
+	TypeObject: true, TypeMap: true, TypeDate: true, TypeTime: true, TypeDateTime: true, TypeDuration: true,
+	TypeArray: true, TypeArrayObject: true, TypeArrayArray: true, TypeArrayInt: true, TypeArrayBigInt: true,
+	TypeArrayString: true, TypeArrayBool: true, TypeArrayFloat: true,
+}
+
+// IsBuiltinType reports whether the value is a type of the parser
+func IsBuiltinType(value string) bool {
+	return builtinTypes[value]
+}
+
+// TypeFormatter formats the type, ex. by the type mapping of a language
+type TypeFormatter func(t Type) (string, error)
 
 type TypeFormatters struct {
 	Type TypeFormatter
@@ -55,9 +77,23 @@ type TypeFormatters struct {
 }
 
 type Meta struct {
-	Key        Key
-	Type       Type
-	Properties []*Property
+	Key Key
+	// OriginalKey is the key of the object in data, Key may be changed by formatting
+	OriginalKey Key
+	// Path is the JSON path of the object in data, ex. $.addresses[*]
+	Path Path
+	// FixedKey keeps Key as is on formatting, ex. a class name set by overrides
+	FixedKey bool
+	// Attributes are arbitrary values for templates, ex. set by overrides
+	Attributes map[string]interface{}
+	// Discriminator is the original key of the property whose value tells the Variants apart, ex. type
+	Discriminator Key
+	// Variants are the classes of the polymorphic array elements, the meta holds their common properties
+	Variants []*Meta
+	// DiscriminatorValue is the discriminator property value of the variant, ex. click
+	DiscriminatorValue string
+	Type               Type
+	Properties         []*Property
 }
 
 func (m *Meta) Sort() {
@@ -65,31 +101,182 @@ func (m *Meta) Sort() {
 }
 
 func (m *Meta) Clone() *Meta {
+	clones := map[*Meta]*Meta{}
+	nm := m.clone(clones)
+
+	// references are resolved after the whole tree is cloned
+	for _, c := range clones {
+		for _, property := range c.Properties {
+			if ref, ok := clones[property.Ref]; ok {
+				property.Ref = ref
+			}
+		}
+	}
+
+	return nm
+}
+
+func (m *Meta) clone(clones map[*Meta]*Meta) *Meta {
 	if m == nil {
 		return nil
 	}
 
 	nm := &Meta{
-		Key:        m.Key,
-		Type:       m.Type,
-		Properties: make([]*Property, len(m.Properties)),
+		Key:         m.Key,
+		OriginalKey: m.OriginalKey,
+		Path:        m.Path,
+		FixedKey:    m.FixedKey,
+		Attributes:  cloneAttributes(m.Attributes),
+		Type:        m.Type.Clone(),
+		Properties:  make([]*Property, len(m.Properties)),
+
+		Discriminator:      m.Discriminator,
+		DiscriminatorValue: m.DiscriminatorValue,
 	}
+	clones[m] = nm
 
 	for i, property := range m.Properties {
 		nm.Properties[i] = &Property{
-			Nest: property.Nest.Clone(),
-			Key:  property.Key,
-			Type: property.Type,
+			Nest:        property.Nest.clone(clones),
+			Ref:         property.Ref,
+			Key:         property.Key,
+			OriginalKey: property.OriginalKey,
+			Path:        property.Path,
+			FixedKey:    property.FixedKey,
+			Optional:    property.Optional,
+			Attributes:  cloneAttributes(property.Attributes),
+			Type:        property.Type.Clone(),
 		}
 	}
 
+	for _, variant := range m.Variants {
+		nm.Variants = append(nm.Variants, variant.clone(clones))
+	}
+
 	return nm
 }
 
+func cloneAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(attributes))
+	for k, v := range attributes {
+		c[k] = v
+	}
+	return c
+}
+
+// Classes returns the meta and all classes nested in it, parents before children
+func (m *Meta) Classes() []*Meta {
+	classes := []*Meta{m}
+	for _, property := range m.Properties {
+		if property.Nest != nil {
+			classes = append(classes, property.Nest.Classes()...)
+		}
+	}
+	for _, variant := range m.Variants {
+		classes = append(classes, variant.Classes()...)
+	}
+	return classes
+}
+
+// CheckTypes checks the types of the properties of the meta and of all classes nested in it, see Type.Check
+func (m *Meta) CheckTypes() error {
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			if err := property.Type.Check(); err != nil {
+				return errors.WithMessagef(err, "can't format type of \"%s\"", property.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// DependencyOrder returns the meta and all classes nested in it, every class after the classes it uses,
+// except for the recursive ones
+func (m *Meta) DependencyOrder() []*Meta {
+	var classes []*Meta
+	visited := map[*Meta]bool{}
+	var visit func(class *Meta)
+	visit = func(class *Meta) {
+		if class == nil || visited[class] {
+			return
+		}
+		// visited before the dependencies, so that cycles are broken
+		visited[class] = true
+		for _, property := range class.Properties {
+			visit(property.Nest)
+			visit(property.Ref)
+		}
+		for _, variant := range class.Variants {
+			visit(variant)
+		}
+		classes = append(classes, class)
+	}
+	visit(m)
+	return classes
+}
+
+// IsPolymorphic reports whether the meta is a base of variants told apart by the discriminator
+func (m *Meta) IsPolymorphic() bool {
+	return len(m.Variants) > 0
+}
+
+// DiscriminatorProperty returns the discriminator property of the polymorphic meta or of the variant
+func (m *Meta) DiscriminatorProperty() *Property {
+	if m.Discriminator == "" {
+		return nil
+	}
+	for _, property := range m.Properties {
+		if property.OriginalKey == m.Discriminator {
+			return property
+		}
+	}
+	return nil
+}
+
+// Fingerprint returns the structure of the meta regardless of its class name and order of properties,
+// metas with equal fingerprints describe the same shape.
+func (m *Meta) Fingerprint() string {
+	props := make([]string, 0, len(m.Properties))
+	for _, property := range m.Properties {
+		fp := property.Key.String() + ":" + property.Type.fingerprint()
+		if property.Optional {
+			fp += "?"
+		}
+		if property.Nest != nil {
+			fp += "{" + property.Nest.Fingerprint() + "}"
+		}
+		if property.Ref != nil {
+			fp += "@" + property.Ref.Key.String()
+		}
+		props = append(props, strconv.Quote(fp))
+	}
+	sort.Strings(props)
+	for _, variant := range m.Variants {
+		props = append(props, strconv.Quote(variant.DiscriminatorValue)+"{"+variant.Fingerprint()+"}")
+	}
+	return strings.Join(props, ",")
+}
+
 type Property struct {
 	Nest *Meta
-	Key  Key
-	Type Type
+	// Ref is the class this property refers to, which is defined by another property of the tree.
+	// Such property has no Nest, so that every class is defined once.
+	Ref *Meta
+	Key Key
+	// OriginalKey is the key of the property in data, Key may be changed by formatting
+	OriginalKey Key
+	// Path is the JSON path of the property in data, ex. $.addresses[*].city
+	Path Path
+	// FixedKey keeps Key as is on formatting, ex. a property name set by overrides
+	FixedKey bool
+	// Optional marks the property missing in some of the merged objects of data
+	Optional bool
+	// Attributes are arbitrary values for templates, ex. set by overrides
+	Attributes map[string]interface{}
+	Type       Type
 }
 
 type Key string
@@ -160,16 +347,115 @@ func (k Key) MergeCaseAcronym() string {
 
 type Type struct {
 	Formatters *TypeFormatters `json:"formatters"`
+	// Elem is the element type of an array or the value type of a map
+	Elem *Type `json:"elem,omitempty"`
+	// Inline is the anonymous object of the type, which has no class of its own
+	Inline *Meta `json:"inline,omitempty"`
+	// Nullable marks the type of values being null in some of the merged objects of data
+	Nullable bool `json:"nullable,omitempty"`
+	// Optional marks the type of a property missing in some of the merged objects of data, see Property.Optional
+	Optional bool `json:"optional,omitempty"`
+	// Recursive marks the type of an object nested in an object of the same class, ex. children of a tree
+	Recursive bool `json:"recursive,omitempty"`
 
 	Key   Key    `json:"key"`
 	Value string `json:"value"`
 }
 
+// Clone returns a deep copy of the type
+func (t Type) Clone() Type {
+	if t.Elem != nil {
+		elem := t.Elem.Clone()
+		t.Elem = &elem
+	}
+	if t.Inline != nil {
+		t.Inline = t.Inline.Clone()
+	}
+	return t
+}
+
+func (t Type) fingerprint() string {
+	fp := t.Value
+	if t.Elem != nil {
+		fp += "<" + t.Elem.fingerprint() + ">"
+	}
+	if t.Nullable {
+		fp += "|null"
+	}
+	return fp
+}
+
+// SetKey sets the key of the type and of all its element types
+func (t *Type) SetKey(key Key) {
+	for ; t != nil; t = t.Elem {
+		t.Key = key
+	}
+}
+
+// SetValue changes the type value, the element type is reset to the element of the new array type
+func (t *Type) SetValue(value string) {
+	if t.Value == value {
+		return
+	}
+	t.Value = value
+	t.Elem = nil
+	if elem, ok := arrayElemTypes[value]; ok {
+		t.Elem = &Type{Key: t.Key, Value: elem}
+	} else if value == TypeArray || value == TypeMap || value == TypeArrayArray {
+		t.Elem = &Type{Key: t.Key, Value: TypeNull}
+	}
+}
+
+// SetFormatters sets the formatters of the type and of all its element types
+func (t *Type) SetFormatters(formatters *TypeFormatters) {
+	for ; t != nil; t = t.Elem {
+		t.Formatters = formatters
+	}
+}
+
+// String returns the formatted type, the type value if the type isn't formatted.
+// A stringer can't fail templates, so the types are checked before rendering, see Meta.CheckTypes
 func (t Type) String() string {
-	return t.Formatters.Type(t)
+	if t.Formatters == nil || t.Formatters.Type == nil {
+		return t.Value
+	}
+	typ, err := t.Formatters.Type(t)
+	if err != nil {
+		return t.Value
+	}
+	return typ
 }
-func (t Type) Doc() string {
-	return t.Formatters.Doc(t)
+
+// Doc returns the formatted doc type, the formatted type without a doc formatter.
+// The errors of formatting and of an unformatted type fail the execution of templates
+func (t Type) Doc() (string, error) {
+	switch {
+	case t.Formatters != nil && t.Formatters.Doc != nil:
+		return t.Formatters.Doc(t)
+	case t.Formatters != nil && t.Formatters.Type != nil:
+		return t.Formatters.Type(t)
+	}
+	return "", errors.Errorf("type %s has no formatters", t.Value)
+}
+
+// Check formats the type and its element types by all its formatters, it returns the first error
+func (t Type) Check() error {
+	for typ := &t; typ != nil && typ.Formatters != nil; typ = typ.Elem {
+		for _, format := range []TypeFormatter{typ.Formatters.Type, typ.Formatters.Doc} {
+			if format == nil {
+				continue
+			}
+			if _, err := format(*typ); err != nil {
+				return err
+			}
+		}
+		if typ.Inline != nil {
+			if err := typ.Inline.CheckTypes(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 func (t Type) IsNull() bool {
 	return t.Value == TypeNull
@@ -177,6 +463,9 @@ func (t Type) IsNull() bool {
 func (t Type) IsInt() bool {
 	return t.Value == TypeInt
 }
+func (t Type) IsBigInt() bool {
+	return t.Value == TypeBigInt
+}
 func (t Type) IsBool() bool {
 	return t.Value == TypeBool
 }
@@ -188,14 +477,34 @@ func (t Type) IsString() bool {
 }
 func (t Type) IsArray() bool {
 	return t.Value == TypeArray || t.Value == TypeArrayObject || t.Value == TypeArrayFloat ||
-		t.Value == TypeArrayBool || t.Value == TypeArrayString || t.Value == TypeArrayInt
+		t.Value == TypeArrayBool || t.Value == TypeArrayString || t.Value == TypeArrayInt ||
+		t.Value == TypeArrayBigInt || t.Value == TypeArrayArray
 }
 func (t Type) IsArrayObject() bool {
 	return t.Value == TypeArrayObject
 }
+func (t Type) IsArrayArray() bool {
+	return t.Value == TypeArrayArray
+}
 func (t Type) IsObject() bool {
 	return t.Value == TypeObject
 }
+
+// IsInline reports whether the type is an anonymous object
+func (t Type) IsInline() bool {
+	return t.Inline != nil
+}
+func (t Type) IsMap() bool {
+	return t.Value == TypeMap
+}
+
+// HasObject reports whether the type is an object or holds objects as elements
+func (t Type) HasObject() bool {
+	if t.IsObject() || t.IsArrayObject() {
+		return true
+	}
+	return t.Elem != nil && t.Elem.HasObject()
+}
 func (t Type) IsTime() bool {
 	return t.Value == TypeTime
 }
@@ -234,6 +543,9 @@ func TypeOf(key Key, v interface{}) Type {
 	case int, int8, int16, int32, int64:
 		t.Value = TypeInt
 		return t
+	case json.Number:
+		t.Value = typeOfNumber(vType)
+		return t
 	case string:
 		if vType == "" {
 			t.Value = TypeString
@@ -260,6 +572,31 @@ func TypeOf(key Key, v interface{}) Type {
 	}
 }
 
+// TypeOfElements returns the common type of values, e.g. the values of a map.
+// Null values are skipped, ints are widened to floats and big ints, other mixed values result in TypeNull.
+func TypeOfElements(key Key, values []interface{}) Type {
+	t := Type{Key: key, Value: TypeNull}
+	for _, v := range values {
+		vt := TypeOf(key, v)
+		switch {
+		case vt.IsNull():
+			continue
+		case t.IsNull() || t.Value == vt.Value:
+			t = vt
+		case numberRank[t.Value] > 0 && numberRank[vt.Value] > 0:
+			if numberRank[vt.Value] > numberRank[t.Value] {
+				t = vt
+			}
+		default:
+			return Type{Key: key, Value: TypeNull}
+		}
+	}
+	return t
+}
+
+// numberRank orders number types by the values they can hold, zero is not a number
+var numberRank = map[string]int{TypeInt: 1, TypeBigInt: 2, TypeFloat: 3}
+
 //nolint:gocyclo
 func typeOfArray(key Key, arr []interface{}) Type {
 	t := Type{Key: key}
@@ -268,23 +605,39 @@ func typeOfArray(key Key, arr []interface{}) Type {
 		TypeArrayBool:   0,
 		TypeArrayFloat:  0,
 		TypeArrayInt:    0,
+		TypeArrayBigInt: 0,
 		TypeArrayString: 0,
 		TypeArrayObject: 0,
+		TypeArrayArray:  0,
 		TypeArray:       0,
 	}
 
+	// elements of the nested arrays
+	var nested []interface{}
+
 	for _, v := range arr {
 		switch vType := v.(type) {
 		case *dynjson.Object:
 			mx[TypeArrayObject]++
 		case *dynjson.Array:
-			mx[typeOfArray(key, vType.Elements).Value]++
+			mx[TypeArrayArray]++
+			nested = append(nested, vType.Elements...)
 		case map[string]interface{}:
 			mx[TypeArrayObject]++
 		case []interface{}:
-			mx[typeOfArray(key, vType).Value]++
+			mx[TypeArrayArray]++
+			nested = append(nested, vType...)
 		case int, int8, int16, int32, int64:
 			mx[TypeArrayInt]++
+		case json.Number:
+			switch typeOfNumber(vType) {
+			case TypeInt:
+				mx[TypeArrayInt]++
+			case TypeBigInt:
+				mx[TypeArrayBigInt]++
+			default:
+				mx[TypeArrayFloat]++
+			}
 		case float32, float64:
 			mx[TypeArrayInt] = 0
 			mx[TypeArrayFloat]++
@@ -305,7 +658,8 @@ func typeOfArray(key Key, arr []interface{}) Type {
 				mx[TypeArrayFloat] = 0
 				mx[TypeArrayBool]++
 			} else {
-				if mx[TypeArrayInt] > 0 || mx[TypeArrayFloat] > 0 || mx[TypeArrayBool] > 0 || mx[TypeArrayObject] > 0 {
+				if mx[TypeArrayInt] > 0 || mx[TypeArrayFloat] > 0 || mx[TypeArrayBool] > 0 || mx[TypeArrayObject] > 0 ||
+					mx[TypeArrayArray] > 0 {
 					// Then array have a mixed types
 					mx[TypeArray]++
 				} else {
@@ -317,18 +671,74 @@ func typeOfArray(key Key, arr []interface{}) Type {
 		}
 	}
 
-	if mx[TypeArray] > 0 {
+	// Nested arrays can't be mixed with other elements
+	if mx[TypeArrayArray] > 0 && mx[TypeArrayArray] < len(arr) {
+		mx[TypeArray]++
+	}
+
+	if mx[TypeArray] > 0 || len(arr) == 0 {
 		t.Value = TypeArray
+		t.Elem = &Type{Key: key, Value: TypeNull}
 		return t
 	}
 
+	// A single float makes the whole number array float and a single big integer makes the whole integer
+	// array big, whatever the order of elements, otherwise precision is lost
+	switch {
+	case mx[TypeArrayFloat] > 0:
+		mx[TypeArrayFloat] += mx[TypeArrayInt] + mx[TypeArrayBigInt]
+		mx[TypeArrayInt], mx[TypeArrayBigInt] = 0, 0
+	case mx[TypeArrayBigInt] > 0:
+		mx[TypeArrayBigInt] += mx[TypeArrayInt]
+		mx[TypeArrayInt] = 0
+	}
+
+	// ties are broken by the priority of types, not by the random order of the map
 	max := 0
-	for k, v := range mx {
-		if v > max {
-			max = v
+	for _, k := range arrayTypePriority {
+		if mx[k] > max {
+			max = mx[k]
 			t.Value = k
 		}
 	}
 
+	if t.IsArrayArray() {
+		elem := typeOfArray(key, nested)
+		t.Elem = &elem
+	} else {
+		t.Elem = &Type{Key: key, Value: arrayElemTypes[t.Value]}
+	}
+
 	return t
 }
+
+// arrayTypePriority orders array types of the same number of elements
+var arrayTypePriority = []string{
+	TypeArrayBigInt, TypeArrayFloat, TypeArrayInt, TypeArrayString, TypeArrayBool, TypeArrayObject, TypeArrayArray,
+}
+
+// arrayElemTypes maps array types of scalars and objects to the type of their elements
+var arrayElemTypes = map[string]string{
+	TypeArrayBool:   TypeBool,
+	TypeArrayFloat:  TypeFloat,
+	TypeArrayInt:    TypeInt,
+	TypeArrayBigInt: TypeBigInt,
+	TypeArrayString: TypeString,
+	TypeArrayObject: TypeObject,
+}
+
+// maxSafeInteger is the largest integer such that it and all smaller integers are exactly representable by float64
+var maxSafeInteger = big.NewInt(1<<53 - 1)
+
+// typeOfNumber classifies a JSON number literal without passing it through float64.
+// Integer literals that can't round-trip through float64 are classified as TypeBigInt.
+func typeOfNumber(n json.Number) string {
+	i, ok := new(big.Int).SetString(n.String(), 10)
+	if !ok {
+		return TypeFloat
+	}
+	if i.CmpAbs(maxSafeInteger) > 0 {
+		return TypeBigInt
+	}
+	return TypeInt
+}
@@ -0,0 +1,134 @@
+package meta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestTypeOfNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		typ    string
+	}{
+		{"0", TypeInt},
+		{"-1", TypeInt},
+		{"9007199254740991", TypeInt},
+		{"-9007199254740991", TypeInt},
+		{"9007199254740992", TypeBigInt},
+		{"-9007199254740992", TypeBigInt},
+		{"18446744073709551616", TypeBigInt},
+		{"1.0", TypeFloat},
+		{"1e3", TypeFloat},
+		{"-0.5", TypeFloat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if typ := TypeOf("", json.Number(tt.number)).Value; typ != tt.typ {
+				t.Errorf("type %s, want %s", typ, tt.typ)
+			}
+		})
+	}
+}
+
+func TestTypeOfElements(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []interface{}
+		typ    string
+	}{
+		{"ints", []interface{}{json.Number("1"), json.Number("2")}, TypeInt},
+		{"int and big int", []interface{}{json.Number("1"), json.Number("9007199254740993")}, TypeBigInt},
+		{"int and float", []interface{}{json.Number("1"), json.Number("1.5")}, TypeFloat},
+		{"big int and float", []interface{}{json.Number("9007199254740993"), json.Number("1.5")}, TypeFloat},
+		{"nulls are skipped", []interface{}{nil, json.Number("1")}, TypeInt},
+		{"mixed", []interface{}{json.Number("1"), "a"}, TypeNull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if typ := TypeOfElements("", tt.values).Value; typ != tt.typ {
+				t.Errorf("type %s, want %s", typ, tt.typ)
+			}
+		})
+	}
+}
+
+func TestIsBuiltinType(t *testing.T) {
+	tests := []struct {
+		value   string
+		builtin bool
+	}{
+		{TypeString, true},
+		{TypeDateTime, true},
+		{TypeArrayBigInt, true},
+		{"uuid", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if builtin := IsBuiltinType(tt.value); builtin != tt.builtin {
+				t.Errorf("builtin %v, want %v", builtin, tt.builtin)
+			}
+		})
+	}
+}
+
+func TestTypeFormatErrors(t *testing.T) {
+	fail := func(t Type) (string, error) { return "", errors.Errorf("no mapping of %s", t.Value) }
+	ok := func(t Type) (string, error) { return "T", nil }
+	tests := []struct {
+		name       string
+		formatters *TypeFormatters
+		str        string
+		docErr     bool
+		checkErr   bool
+	}{
+		{"unformatted", nil, TypeInt, true, false},
+		{"formatted", &TypeFormatters{Type: ok}, "T", false, false},
+		{"failed type", &TypeFormatters{Type: fail}, TypeInt, true, true},
+		{"failed doc", &TypeFormatters{Type: ok, Doc: fail}, "T", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := Type{Value: TypeInt, Formatters: tt.formatters}
+			if s := typ.String(); s != tt.str {
+				t.Errorf("string %s, want %s", s, tt.str)
+			}
+			if _, err := typ.Doc(); (err != nil) != tt.docErr {
+				t.Errorf("doc error %v, want error %v", err, tt.docErr)
+			}
+			if err := typ.Check(); (err != nil) != tt.checkErr {
+				t.Errorf("check error %v, want error %v", err, tt.checkErr)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := &Meta{Key: "a", Properties: []*Property{
+		{Key: "x", Type: Type{Value: TypeInt}},
+		{Key: "y", Type: Type{Value: TypeString}},
+	}}
+	b := &Meta{Key: "b", Properties: []*Property{
+		{Key: "y", Type: Type{Value: TypeString}},
+		{Key: "x", Type: Type{Value: TypeInt}},
+	}}
+	c := &Meta{Key: "a", Properties: []*Property{
+		{Key: "x", Type: Type{Value: TypeInt}},
+		{Key: "y", Type: Type{Value: TypeInt}},
+	}}
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("the order of properties changes the fingerprint")
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("types of properties don't change the fingerprint")
+	}
+	d := &Meta{Key: "a", Properties: []*Property{
+		{Key: "x", Type: Type{Value: TypeInt}},
+		{Key: "y", Type: Type{Value: TypeString}, Optional: true},
+	}}
+	if a.Fingerprint() == d.Fingerprint() {
+		t.Error("optional properties don't change the fingerprint")
+	}
+}
@@ -0,0 +1,109 @@
+package meta
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Path is a JSON path of a value in data, ex. $.addresses[*].coordinates
+type Path string
+
+// RootPath is the path of the data root
+const RootPath Path = "$"
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (p Path) String() string {
+	return string(p)
+}
+
+// Child returns the path of the object member, ex. $.key or $["first name"]
+func (p Path) Child(key string) Path {
+	if identifierRe.MatchString(key) {
+		return p + "." + Path(key)
+	}
+	return p + "[" + Path(strconv.Quote(key)) + "]"
+}
+
+// Elem returns the path of the array elements, ex. $.addresses[*]
+func (p Path) Elem() Path {
+	return p + "[*]"
+}
+
+// Filter returns the path of the array elements whose member key equals the value, ex. $.events[?(@.type=="click")]
+func (p Path) Filter(key, value string) Path {
+	filter := "?(" + Path("@").Child(key) + "==" + Path(strconv.Quote(value)) + ")"
+	return Path(strings.TrimSuffix(p.String(), "[*]")) + "[" + filter + "]"
+}
+
+// Values returns the path of the map values, ex. $.prices.*
+func (p Path) Values() Path {
+	return p + ".*"
+}
+
+// PathPattern matches JSON paths by a glob:
+//   - [*] matches the elements of arrays, filtered ones too, and .* matches the values of maps or any member of an object
+//   - * inside a key matches any characters of the key, ex. $.tracking_*
+//   - .. matches any number of path segments, ex. $..id
+//
+// Patterns without the leading $ are relative to the root, ex. addresses[*].city
+type PathPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// CompilePathPattern parses the glob of JSON paths
+func CompilePathPattern(pattern string) (*PathPattern, error) {
+	p := pattern
+	switch {
+	case p == "":
+		return nil, errors.New("path pattern is empty")
+	case strings.HasPrefix(p, "$"):
+	case strings.HasPrefix(p, "["):
+		p = "$" + p
+	default:
+		p = "$." + p
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("^")
+	for i := 0; i < len(p); {
+		switch {
+		case strings.HasPrefix(p[i:], "[*]"):
+			b.WriteString(`\[(?:\*|\?\(.*?\))\]`)
+			i += 3
+		case strings.HasPrefix(p[i:], ".."):
+			b.WriteString(`(?:\.|\[|\..*[.\[])`)
+			i += 2
+		case strings.HasPrefix(p[i:], ".*") && (i+2 == len(p) || p[i+2] == '.' || p[i+2] == '['):
+			// any member including the quoted ones
+			b.WriteString(`(?:\.[^.\[]*|\["(?:[^"\\]|\\.)*"\])`)
+			i += 2
+		case p[i] == '*':
+			b.WriteString(`[^.\[]*`)
+			i++
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+			i++
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid path pattern \"%s\"", pattern)
+	}
+	return &PathPattern{pattern: pattern, re: re}, nil
+}
+
+func (p *PathPattern) String() string {
+	return p.pattern
+}
+
+// Match reports whether the path matches the pattern
+func (p *PathPattern) Match(path Path) bool {
+	return p.re.MatchString(path.String())
+}
@@ -0,0 +1,32 @@
+package meta
+
+import "testing"
+
+func TestPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    Path
+		match   bool
+	}{
+		{"$.a", "$.a", true},
+		{"$.a", "$.a.b", false},
+		{"a.b", "$.a.b", true},
+		{"$..id", "$.a[*].id", true},
+		{"$..id", "$.id", true},
+		{"$.tracking_*", "$.tracking_id", true},
+		{"$.events[*].x", `$.events[?(@.type=="click")].x`, true},
+		{"$.prices.*", "$.prices.*", true},
+		{"$.a.*", "$.a.b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path.String(), func(t *testing.T) {
+			p, err := CompilePathPattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match := p.Match(tt.path); match != tt.match {
+				t.Errorf("match %v, want %v", match, tt.match)
+			}
+		})
+	}
+}
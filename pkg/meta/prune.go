@@ -0,0 +1,50 @@
+package meta
+
+// Prune drops the properties matched by exclude patterns and, if there are include patterns, the properties
+// that neither match them nor hold matching properties. Properties nested in an included one are kept.
+// It returns the paths of dropped properties, a dropped subtree is reported by its top path.
+func (m *Meta) Prune(include, exclude []*PathPattern) []Path {
+	return m.prune(include, exclude, len(include) == 0)
+}
+
+func (m *Meta) prune(include, exclude []*PathPattern, included bool) []Path {
+	var dropped []Path
+	properties := m.Properties[:0]
+	for _, property := range m.Properties {
+		if matchAny(exclude, property.Path) || property.Nest != nil && matchAny(exclude, property.Nest.Path) {
+			dropped = append(dropped, property.Path)
+			continue
+		}
+
+		inc := included || matchAny(include, property.Path)
+		if property.Nest != nil {
+			inc = inc || matchAny(include, property.Nest.Path)
+			nested := property.Nest.prune(include, exclude, inc)
+			if !inc && len(property.Nest.Properties) == 0 {
+				// the property was kept for its descendants only, but none of them is included
+				dropped = append(dropped, property.Path)
+				continue
+			}
+			dropped = append(dropped, nested...)
+		} else if !inc {
+			dropped = append(dropped, property.Path)
+			continue
+		}
+		properties = append(properties, property)
+	}
+	m.Properties = properties
+
+	for _, variant := range m.Variants {
+		dropped = append(dropped, variant.prune(include, exclude, included)...)
+	}
+	return dropped
+}
+
+func matchAny(patterns []*PathPattern, path Path) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package meta
+
+import (
+	"strings"
+	"testing"
+)
+
+// testTree is {"id": 1, "user": {"name": "a", "password": "b"}, "tags": ["a"]}
+func testTree() *Meta {
+	user := &Meta{Key: "user", Path: "$.user", Properties: []*Property{
+		{Key: "name", Path: "$.user.name", Type: Type{Value: TypeString}},
+		{Key: "password", Path: "$.user.password", Type: Type{Value: TypeString}},
+	}}
+	return &Meta{Key: "root", Path: RootPath, Properties: []*Property{
+		{Key: "id", Path: "$.id", Type: Type{Value: TypeInt}},
+		{Key: "user", Path: "$.user", Type: Type{Value: TypeObject}, Nest: user},
+		{Key: "tags", Path: "$.tags", Type: Type{Value: TypeArrayString}},
+	}}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		dropped          []string
+	}{
+		{"nothing", nil, nil, nil},
+		{"exclude", nil, []string{"$..password"}, []string{"$.user.password"}},
+		{"exclude subtree", nil, []string{"$.user"}, []string{"$.user"}},
+		{"include", []string{"$.user.name"}, nil, []string{"$.id", "$.user.password", "$.tags"}},
+		{"include subtree", []string{"$.user"}, nil, []string{"$.id", "$.tags"}},
+		{"include and exclude", []string{"$.user"}, []string{"$..password"}, []string{"$.id", "$.user.password", "$.tags"}},
+	}
+	compile := func(t *testing.T, patterns []string) []*PathPattern {
+		var compiled []*PathPattern
+		for _, pattern := range patterns {
+			p, err := CompilePathPattern(pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compiled = append(compiled, p)
+		}
+		return compiled
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dropped []string
+			for _, path := range testTree().Prune(compile(t, tt.include), compile(t, tt.exclude)) {
+				dropped = append(dropped, path.String())
+			}
+			if strings.Join(dropped, ",") != strings.Join(tt.dropped, ",") {
+				t.Errorf("dropped %v, want %v", dropped, tt.dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package override
+
+import (
+	"io"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule overrides the properties and classes matched by its path
+type Rule struct {
+	// Path is a JSON path or a glob of paths, ex. $.addresses[*].coordinates, see meta.PathPattern
+	Path string `json:"path" yaml:"path" xml:"Path"`
+	// Name renames the property, the name isn't formatted
+	Name string `json:"name" yaml:"name" xml:"Name"`
+	// ClassName renames the class of the object, of the property object or of the property elements.
+	// The name isn't formatted
+	ClassName string `json:"className" yaml:"className" xml:"ClassName"`
+	// Type overrides the property type, ex. string or a custom type of TypeMapping.Custom like uuid
+	Type string `json:"type" yaml:"type" xml:"Type"`
+	// Attributes are arbitrary values available in templates as .Attributes
+	Attributes map[string]interface{} `json:"attributes" yaml:"attributes" xml:"-"`
+
+	pattern *meta.PathPattern
+}
+
+// Overrides are rules applied in order, the later rules take precedence over the earlier ones
+type Overrides struct {
+	Rules []*Rule `json:"rules" yaml:"rules" xml:"Rules"`
+}
+
+// Load reads overrides in YAML or JSON keyed by paths. The types of rules must be the types of the parser,
+// see meta.IsBuiltinType, or customTypes, the keys of TypeMapping.Custom. Ex.
+//
+//	$.ip_address:
+//	  name: IP
+//	$.addresses[*].coordinates:
+//	  className: GeoPoint
+//	$..id:
+//	  type: uuid
+//	  attributes:
+//	    primary: true
+func Load(r io.Reader, customTypes ...string) (*Overrides, error) {
+	doc := &yaml.Node{}
+	if err := yaml.NewDecoder(r).Decode(doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &Overrides{}, nil
+		}
+		return nil, errors.WithMessage(err, "can't decode overrides")
+	}
+	if len(doc.Content) == 0 {
+		return &Overrides{}, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.Errorf("overrides must be a mapping of paths to rules, line %d", root.Line)
+	}
+
+	overrides := &Overrides{Rules: make([]*Rule, 0, len(root.Content)/2)}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		rule := &Rule{}
+		if err := root.Content[i+1].Decode(rule); err != nil {
+			return nil, errors.WithMessagef(err, "can't decode override rule \"%s\"", root.Content[i].Value)
+		}
+		rule.Path = root.Content[i].Value
+		overrides.Rules = append(overrides.Rules, rule)
+	}
+	if err := overrides.compile(); err != nil {
+		return nil, err
+	}
+	if err := overrides.checkTypes(customTypes); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// checkTypes reports the first rule of an unknown type, it would have no type mapping in templates
+func (o *Overrides) checkTypes(customTypes []string) error {
+	custom := make(map[string]bool, len(customTypes))
+	for _, typ := range customTypes {
+		custom[typ] = true
+	}
+	for _, rule := range o.Rules {
+		if rule.Type != "" && !meta.IsBuiltinType(rule.Type) && !custom[rule.Type] {
+			return errors.Errorf("invalid override rule \"%s\": unknown type \"%s\"", rule.Path, rule.Type)
+		}
+	}
+	return nil
+}
+
+func (o *Overrides) compile() error {
+	for _, rule := range o.Rules {
+		if rule.pattern != nil {
+			continue
+		}
+		pattern, err := meta.CompilePathPattern(rule.Path)
+		if err != nil {
+			return errors.WithMessage(err, "invalid override rule")
+		}
+		rule.pattern = pattern
+	}
+	return nil
+}
+
+// Apply overrides the meta tree, it must be applied before formatting
+func (o *Overrides) Apply(m *meta.Meta) error {
+	if err := o.compile(); err != nil {
+		return err
+	}
+
+	// the [*] of patterns matches the filtered paths of variants too, ex. $.events[?(@.type=="click")],
+	// but the class rules of the base don't rename its variants
+	bases := map[*meta.Meta]*meta.Meta{}
+	for _, class := range m.Classes() {
+		for _, variant := range class.Variants {
+			bases[variant] = class
+		}
+	}
+
+	for _, class := range m.Classes() {
+		for _, rule := range o.Rules {
+			if !rule.pattern.Match(class.Path) {
+				continue
+			}
+			if base, ok := bases[class]; ok && rule.pattern.Match(base.Path) {
+				continue
+			}
+			rule.applyClass(class)
+		}
+		for _, property := range class.Properties {
+			for _, rule := range o.Rules {
+				if rule.pattern.Match(property.Path) {
+					rule.applyProperty(property)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Rule) applyClass(class *meta.Meta) {
+	if r.ClassName != "" {
+		class.Key = meta.Key(r.ClassName)
+		class.FixedKey = true
+	}
+	class.Attributes = mergeAttributes(class.Attributes, r.Attributes)
+}
+
+func (r *Rule) applyProperty(property *meta.Property) {
+	if r.Name != "" {
+		property.Key = meta.Key(r.Name)
+		property.FixedKey = true
+	}
+	if r.Type != "" && r.Type != property.Type.Value {
+		property.Type.SetValue(r.Type)
+		if !property.Type.HasObject() {
+			property.Nest = nil
+			property.Ref = nil
+		}
+	}
+	if r.ClassName != "" {
+		switch {
+		case property.Nest != nil:
+			property.Nest.Key = meta.Key(r.ClassName)
+			property.Nest.FixedKey = true
+		case property.Ref != nil:
+			property.Ref.Key = meta.Key(r.ClassName)
+			property.Ref.FixedKey = true
+		}
+	}
+	property.Attributes = mergeAttributes(property.Attributes, r.Attributes)
+}
+
+func mergeAttributes(dst, src map[string]interface{}) map[string]interface{} {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,77 @@
+package override
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/parser"
+)
+
+func TestApply(t *testing.T) {
+	p, err := parser.NewParserJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := p.Parse([]byte(`{"id": 1, "ip_address": "x", "coordinates": {"lat": 1.5}, "items": [{"id": 2}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := Load(strings.NewReader(`
+$.ip_address:
+  name: IP
+$.coordinates:
+  className: GeoPoint
+$..id:
+  type: string
+  attributes:
+    primary: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := overrides.Apply(m); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := map[meta.Path]*meta.Property{}
+	for _, class := range m.Classes() {
+		for _, property := range class.Properties {
+			properties[property.Path] = property
+		}
+	}
+	if p := properties["$.ip_address"]; p.Key != "IP" || !p.FixedKey {
+		t.Errorf("name %s, fixed %v, want a fixed IP", p.Key, p.FixedKey)
+	}
+	if p := properties["$.coordinates"]; p.Nest == nil || p.Nest.Key != "GeoPoint" {
+		t.Errorf("class of coordinates isn't GeoPoint")
+	}
+	for _, path := range []meta.Path{"$.id", "$.items[*].id"} {
+		p := properties[path]
+		if p.Type.Value != meta.TypeString || p.Attributes["primary"] != true {
+			t.Errorf("%s is %s with attributes %v, want a primary string", path, p.Type.Value, p.Attributes)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []string{
+		"- $.a\n",
+		"$.a:\n  name: [x]\n",
+		"\"\": {name: x}\n",
+		"$.a: {type: guid}\n",
+	}
+	for _, data := range tests {
+		t.Run(data, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(data), "uuid"); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestLoadCustomTypes(t *testing.T) {
+	if _, err := Load(strings.NewReader("$.a: {type: uuid}\n$.b: {type: string}\n"), "uuid"); err != nil {
+		t.Error(err)
+	}
+}
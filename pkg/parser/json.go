@@ -1,103 +1,321 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/nikitaksv/dynjson"
 	"github.com/nikitaksv/gendata/pkg/meta"
 	"github.com/pkg/errors"
 )
 
-type parserJSON struct{}
+var (
+	numericKeyRe = regexp.MustCompile(`^-?\d+$`)
+	uuidKeyRe    = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+type parserJSON struct {
+	// samples are the statistics of merged objects
+	samples map[*dynjson.Object]*sampleStats
+}
+
+// sampleStats counts the objects merged into a sample and their properties
+type sampleStats struct {
+	objects int
+	present map[string]int
+	null    map[string]bool
+}
 
 func NewParserJSON() (Parser, error) {
 	return &parserJSON{}, nil
 }
 
-func (p *parserJSON) Parse(data []byte, _ ...Option) (*meta.Meta, error) {
-	j := &dynjson.Json{}
-	err := json.Unmarshal(data, j)
+func (p *parserJSON) Parse(data []byte, opts ...Option) (*meta.Meta, error) {
+	options := &options{}
+	if err := options.apply(opts...); err != nil {
+		return nil, err
+	}
+
+	j, err := unmarshalJSON(data)
 	if err != nil {
 		return nil, err
 	}
+	if len(options.rootPath) > 0 {
+		if j.Value, err = selectRoot(j.Value, options.rootPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// merged samples are tracked per parsing, so the parser can be used concurrently
+	return (&parserJSON{samples: map[*dynjson.Object]*sampleStats{}}).parse(j, options)
+}
 
+func (p *parserJSON) parse(j *dynjson.Json, options *options) (*meta.Meta, error) {
 	key := meta.Key("")
 
 	// main object
 	obj := &meta.Meta{
 		Key:        key,
+		Path:       meta.RootPath,
 		Type:       meta.TypeOf(key, j.Value),
 		Properties: nil,
 	}
 
 	switch vType := j.Value.(type) {
 	case *dynjson.Object:
-		p.parseMap(obj, vType)
+		p.parseMap(obj, vType, options, nil)
 	case *dynjson.Array:
+		obj.Path = elemPath(obj.Path, obj.Type)
+		if p.parseVariants(obj, vType, options, nil) {
+			break
+		}
 		mergedArr := p.mergeArray(vType)
 		if len(mergedArr.Elements) > 0 {
 			if valMap, ok := mergedArr.Elements[0].(*dynjson.Object); ok {
-				p.parseMap(obj, valMap)
+				p.parseMap(obj, valMap, options, nil)
 			}
 		}
 	default:
-		return nil, errors.New("undefined type json data: " + vType.(string))
+		return nil, errors.Errorf("undefined type json data: %v", vType)
 	}
 
 	return obj, nil
 }
 
-func (p *parserJSON) parseMap(obj *meta.Meta, aMap *dynjson.Object) {
+// ancestor is an object being parsed and its merged sample
+type ancestor struct {
+	meta   *meta.Meta
+	sample *dynjson.Object
+}
+
+func (p *parserJSON) parseMap(obj *meta.Meta, aMap *dynjson.Object, options *options, ancestors []ancestor) {
+	ancestors = append(ancestors[:len(ancestors):len(ancestors)], ancestor{meta: obj, sample: aMap})
 	for _, property := range aMap.Properties {
 		prop := &meta.Property{
-			Key:  meta.Key(property.Key),
-			Type: meta.TypeOf(meta.Key(property.Key), property.Value),
-			Nest: nil,
+			Key:         meta.Key(property.Key),
+			OriginalKey: meta.Key(property.Key),
+			Path:        obj.Path.Child(property.Key),
+			Type:        meta.TypeOf(meta.Key(property.Key), property.Value),
+			Nest:        nil,
 		}
 		if prop.Type.IsObject() || prop.Type.Value == meta.TypeArrayObject {
 			prop.Type.Key = prop.Key
 		}
 
-		switch vType := property.Value.(type) {
-		case *dynjson.Object:
-			nestedObj := &meta.Meta{
-				Key:        prop.Key,
-				Type:       meta.TypeOf(prop.Key, property),
-				Properties: nil,
+		value, path := property.Value, prop.Path
+		if vObj, ok := value.(*dynjson.Object); ok && p.isMap(prop.Key, vObj, options) {
+			prop.Type, value = p.mapOf(prop.Key, vObj)
+			path = path.Values()
+		}
+		if stats, ok := p.samples[aMap]; ok {
+			// the property is missing or null in some of the merged objects
+			prop.Optional = stats.present[property.Key] < stats.objects
+			prop.Type.Optional = prop.Optional
+			prop.Type.Nullable = stats.null[property.Key] && !prop.Type.IsNull()
+		}
+		prop.Nest, prop.Ref = p.parseNest(prop.Key, path, value, options, ancestors)
+		prop.Type.Recursive = prop.Ref != nil
+
+		obj.Properties = append(obj.Properties, prop)
+	}
+}
+
+// parseNest returns the nested object of an object value or of an array of objects, otherwise nil.
+// If the object repeats the shape of an ancestor, the ancestor is returned as a reference instead.
+func (p *parserJSON) parseNest(
+	key meta.Key, path meta.Path, value interface{}, options *options, ancestors []ancestor,
+) (nest, ref *meta.Meta) {
+	nestedObj := &meta.Meta{
+		Key:         key,
+		OriginalKey: key,
+		Type:        meta.TypeOf(key, value),
+		Properties:  nil,
+	}
+	nestedObj.Path = elemPath(path, nestedObj.Type)
+
+	var sample *dynjson.Object
+	switch vType := value.(type) {
+	case *dynjson.Object:
+		sample = vType
+	case *dynjson.Array:
+		mergedArr := p.mergeArray(vType)
+		if len(mergedArr.Elements) > 0 {
+			if valMap, ok := mergedArr.Elements[0].(*dynjson.Object); ok {
+				sample = valMap
 			}
-			p.parseMap(nestedObj, vType)
-			prop.Nest = nestedObj
-		case *dynjson.Array:
-			nestedObj := &meta.Meta{
-				Key:        prop.Key,
-				Type:       meta.TypeOf(prop.Key, property.Value),
-				Properties: nil,
+		}
+	}
+	if sample == nil {
+		return nil, nil
+	}
+
+	if options.recursion {
+		if ref := recursiveRef(key, sample, ancestors); ref != nil {
+			return nil, ref
+		}
+	}
+
+	if arr, ok := value.(*dynjson.Array); ok && p.parseVariants(nestedObj, arr, options, ancestors) {
+		return nestedObj, nil
+	}
+
+	p.parseMap(nestedObj, sample, options, ancestors)
+	return nestedObj, nil
+}
+
+// recursiveRef returns the nearest ancestor whose shape includes the sample, if the sample nests itself
+// under the same key, ex. replies of a comment which have replies too
+func recursiveRef(key meta.Key, sample *dynjson.Object, ancestors []ancestor) *meta.Meta {
+	if _, ok := sample.GetProperty(key.String()); !ok {
+		return nil
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if subShape(sample, ancestors[i].sample) {
+			return ancestors[i].meta
+		}
+	}
+	return nil
+}
+
+// subShape reports whether every property of the sample is a property of the object with a compatible type
+func subShape(sample, obj *dynjson.Object) bool {
+	for _, property := range sample.Properties {
+		objProperty, ok := obj.GetProperty(property.Key)
+		if !ok {
+			return false
+		}
+		t := meta.TypeOf(meta.Key(property.Key), property.Value)
+		objT := meta.TypeOf(meta.Key(property.Key), objProperty.Value)
+		if !compatibleTypes(t, objT) {
+			return false
+		}
+	}
+	return true
+}
+
+func compatibleTypes(a, b meta.Type) bool {
+	isNumber := func(t meta.Type) bool { return t.IsInt() || t.IsBigInt() || t.IsFloat() }
+	switch {
+	case a.Value == b.Value, a.IsNull(), b.IsNull():
+		return true
+	case isNumber(a) && isNumber(b):
+		return true
+	case a.IsArray() && b.IsArray():
+		// an empty or mixed array matches any array
+		return a.Value == meta.TypeArray || b.Value == meta.TypeArray
+	}
+	return false
+}
+
+// elemPath returns the path of the objects in the value of the type, ex. $.matrix[*][*] for arrays of arrays
+func elemPath(path meta.Path, t meta.Type) meta.Path {
+	for ; t.IsArray() && t.Elem != nil; t = *t.Elem {
+		path = path.Elem()
+	}
+	return path
+}
+
+// isMap reports whether the object is a dictionary with dynamic keys rather than a class
+func (p *parserJSON) isMap(key meta.Key, obj *dynjson.Object, options *options) bool {
+	for _, mapKey := range options.mapKeys {
+		if mapKey == key.String() {
+			return true
+		}
+	}
+	if len(obj.Properties) == 0 {
+		return false
+	}
+
+	numeric, uuid := true, true
+	for _, property := range obj.Properties {
+		numeric = numeric && numericKeyRe.MatchString(property.Key)
+		uuid = uuid && uuidKeyRe.MatchString(property.Key)
+	}
+	if numeric || uuid {
+		return true
+	}
+
+	if options.mapMinKeys <= 0 || len(obj.Properties) < options.mapMinKeys {
+		return false
+	}
+
+	// many keys with homogeneous values
+	values := make([]interface{}, 0, len(obj.Properties))
+	for _, property := range obj.Properties {
+		values = append(values, property.Value)
+	}
+	elem := meta.TypeOfElements(key, values)
+	if elem.IsNull() {
+		return false
+	}
+	if elem.IsObject() {
+		var shape []string
+		for _, v := range values {
+			o, ok := v.(*dynjson.Object)
+			if !ok {
+				continue
 			}
-			mergedArr := p.mergeArray(vType)
-			if len(mergedArr.Elements) > 0 {
-				if valMap, ok := mergedArr.Elements[0].(*dynjson.Object); ok {
-					p.parseMap(nestedObj, valMap)
-					prop.Nest = nestedObj
-				}
+			keys := make([]string, 0, len(o.Properties))
+			for _, property := range o.Properties {
+				keys = append(keys, property.Key)
 			}
+			sort.Strings(keys)
+			if shape != nil && strings.Join(keys, ",") != strings.Join(shape, ",") {
+				return false
+			}
+			shape = keys
 		}
+	}
 
-		obj.Properties = append(obj.Properties, prop)
+	return true
+}
+
+// mapOf returns the map type of the object and a sample value of the map elements for nested parsing
+func (p *parserJSON) mapOf(key meta.Key, obj *dynjson.Object) (meta.Type, interface{}) {
+	values := make([]interface{}, 0, len(obj.Properties))
+	for _, property := range obj.Properties {
+		values = append(values, property.Value)
+	}
+
+	elem := meta.TypeOfElements(key, values)
+	t := meta.Type{Key: key, Value: meta.TypeMap, Elem: &elem}
+
+	objects := make([]*dynjson.Object, 0, len(values))
+	elements := &dynjson.Array{}
+	for _, v := range values {
+		switch vType := v.(type) {
+		case *dynjson.Object:
+			objects = append(objects, vType)
+		case *dynjson.Array:
+			elements.Elements = append(elements.Elements, vType.Elements...)
+		}
+	}
+
+	switch {
+	case elem.IsObject():
+		return t, p.mergeMap(objects...)
+	case elem.IsArray():
+		return t, elements
 	}
+	return t, nil
 }
 
 func (p *parserJSON) mergeArray(arr *dynjson.Array) *dynjson.Array {
 	res := &dynjson.Array{}
-	m := &dynjson.Object{}
+	var objects []*dynjson.Object
 	for _, v := range arr.Elements {
 		switch vType := v.(type) {
 		case *dynjson.Object:
-			m = p.mergeMap(vType, m)
+			objects = append(objects, vType)
 		case *dynjson.Array:
 			mergedArr := p.mergeArray(vType)
 			if len(mergedArr.Elements) > 0 {
 				if valMap, ok := mergedArr.Elements[0].(*dynjson.Object); ok {
-					m = p.mergeMap(valMap, m)
+					objects = append(objects, valMap)
 				}
 			}
 		default:
@@ -105,7 +323,7 @@ func (p *parserJSON) mergeArray(arr *dynjson.Array) *dynjson.Array {
 		}
 	}
 
-	if len(m.Properties) > 0 {
+	if m := p.mergeMap(objects...); len(m.Properties) > 0 {
 		res.Elements = append(res.Elements, m)
 	}
 
@@ -114,12 +332,19 @@ func (p *parserJSON) mergeArray(arr *dynjson.Array) *dynjson.Array {
 
 func (p *parserJSON) mergeMap(maps ...*dynjson.Object) *dynjson.Object {
 	result := &dynjson.Object{}
+	stats := &sampleStats{present: map[string]int{}, null: map[string]bool{}}
 	for _, m := range maps {
+		stats.add(p.statsOf(m))
 		for _, property := range m.Properties {
 			existsProp, exists := result.GetProperty(property.Key)
 
 			switch vType := property.Value.(type) {
 			case *dynjson.Array:
+				if exists {
+					if existsArr, ok := existsProp.Value.(*dynjson.Array); ok {
+						vType = &dynjson.Array{Elements: append(append([]interface{}{}, existsArr.Elements...), vType.Elements...)}
+					}
+				}
 				dynjsonSetProperty(result, property.Key, p.mergeArray(vType))
 			case *dynjson.Object:
 				if exists && meta.TypeOf(meta.Key(property.Key), existsProp.Value).IsObject() {
@@ -127,16 +352,50 @@ func (p *parserJSON) mergeMap(maps ...*dynjson.Object) *dynjson.Object {
 				} else {
 					dynjsonSetProperty(result, property.Key, property.Value)
 				}
+			case json.Number:
+				if exists {
+					if existsNum, ok := existsProp.Value.(json.Number); ok {
+						vType = widerNumber(existsNum, vType)
+					}
+				}
+				dynjsonSetProperty(result, property.Key, vType)
 			default:
-				if !exists || !meta.TypeOf(meta.Key(property.Key), property).IsNull() {
+				// null doesn't replace a value, but a value replaces null
+				if !exists || existsProp.Value == nil {
 					dynjsonSetProperty(result, property.Key, property.Value)
 				}
 			}
 		}
 	}
+	if p.samples != nil {
+		p.samples[result] = stats
+	}
 	return result
 }
 
+// statsOf returns the statistics of the merged object or of a single object of data
+func (p *parserJSON) statsOf(obj *dynjson.Object) *sampleStats {
+	if stats, ok := p.samples[obj]; ok {
+		return stats
+	}
+	stats := &sampleStats{objects: 1, present: map[string]int{}, null: map[string]bool{}}
+	for _, property := range obj.Properties {
+		stats.present[property.Key]++
+		stats.null[property.Key] = stats.null[property.Key] || property.Value == nil
+	}
+	return stats
+}
+
+func (s *sampleStats) add(other *sampleStats) {
+	s.objects += other.objects
+	for key, n := range other.present {
+		s.present[key] += n
+	}
+	for key, null := range other.null {
+		s.null[key] = s.null[key] || null
+	}
+}
+
 func dynjsonSetProperty(j *dynjson.Object, k string, v interface{}) {
 	_, ok := j.GetProperty(k)
 	if len(j.Properties) == 0 || !ok {
@@ -149,3 +408,78 @@ func dynjsonSetProperty(j *dynjson.Object, k string, v interface{}) {
 		}
 	}
 }
+
+// widerNumber returns the number whose type can hold both, so merged samples don't lose floats or big integers
+func widerNumber(a, b json.Number) json.Number {
+	if meta.TypeOfElements("", []interface{}{a, b}).Value != meta.TypeOf("", b).Value {
+		return a
+	}
+	return b
+}
+
+// unmarshalJSON decodes data into dynjson values keeping numbers as json.Number literals,
+// so that type inference isn't affected by float64 precision.
+func unmarshalJSON(data []byte) (*dynjson.Json, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := unmarshalValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	// the data is a single value, ex. {"a":1}{"b":2} and {"a":1}] are invalid
+	if token, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Errorf("invalid json data: unexpected %v after the top-level value", token)
+	}
+	return &dynjson.Json{Value: v}, nil
+}
+
+func unmarshalValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := &dynjson.Object{Properties: []*dynjson.Property{}}
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := unmarshalValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Properties = append(obj.Properties, &dynjson.Property{Key: keyToken.(string), Value: v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := &dynjson.Array{Elements: []interface{}{}}
+		for dec.More() {
+			v, err := unmarshalValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements = append(arr.Elements, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+
+	return nil, errors.Errorf("unexpected json delimiter %s", delim)
+}
@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+func parse(t *testing.T, data string, opts ...Option) *meta.Meta {
+	t.Helper()
+	p, err := NewParserJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := p.Parse([]byte(data), opts...)
+	if err != nil {
+		t.Fatalf("parse %s: %v", data, err)
+	}
+	return m
+}
+
+func property(m *meta.Meta, key string) *meta.Property {
+	for _, p := range m.Properties {
+		if string(p.Key) == key {
+			return p
+		}
+	}
+	return nil
+}
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		data string
+		typ  string
+	}{
+		{`{"v": 1}`, meta.TypeInt},
+		{`{"v": -9007199254740991}`, meta.TypeInt},
+		{`{"v": 9007199254740993}`, meta.TypeBigInt},
+		{`{"v": 123456789012345678901234567890}`, meta.TypeBigInt},
+		{`{"v": 1.5}`, meta.TypeFloat},
+		{`{"v": 1e3}`, meta.TypeFloat},
+		{`{"v": [1, 2]}`, meta.TypeArrayInt},
+		{`{"v": [1, 9007199254740993]}`, meta.TypeArrayBigInt},
+		{`{"v": [1, 1.5]}`, meta.TypeArrayFloat},
+		{`{"v": [1.5, 2, 3]}`, meta.TypeArrayFloat},
+		{`{"v": [2, 1.5]}`, meta.TypeArrayFloat},
+		{`{"v": [1.5, 9007199254740993, 1]}`, meta.TypeArrayFloat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			p := property(parse(t, tt.data), "v")
+			if p == nil {
+				t.Fatal("no property v")
+			}
+			if p.Type.Value != tt.typ {
+				t.Errorf("type %s, want %s", p.Type.Value, tt.typ)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`{"a": 1`,
+		`{"a": 1}{"b": 2}`,
+		`{"a": 1}]`,
+		`{"a": 1} x`,
+		`1`,
+	}
+	p, err := NewParserJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range tests {
+		t.Run(data, func(t *testing.T) {
+			if _, err := p.Parse([]byte(data)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestParseMaps(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts []Option
+		typ  string
+		elem string
+	}{
+		{"dynamic keys", `{"v": {"a1": 1, "b2": 2, "c3": 3}}`, []Option{WithMapMinKeys(3)}, meta.TypeMap, meta.TypeInt},
+		{"too few keys", `{"v": {"a1": 1, "b2": 2}}`, []Option{WithMapMinKeys(3)}, meta.TypeObject, ""},
+		{"mixed values", `{"v": {"a1": 1, "b2": "x", "c3": 3}}`, []Option{WithMapMinKeys(3)}, meta.TypeObject, ""},
+		{"map keys", `{"v": {"a": {"x": 1}}}`, []Option{WithMapKeys("v")}, meta.TypeMap, meta.TypeObject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := property(parse(t, tt.data, tt.opts...), "v")
+			if p == nil {
+				t.Fatal("no property v")
+			}
+			if p.Type.Value != tt.typ {
+				t.Errorf("type %s, want %s", p.Type.Value, tt.typ)
+			}
+			if tt.elem != "" && (p.Type.Elem == nil || p.Type.Elem.Value != tt.elem) {
+				t.Errorf("element type %v, want %s", p.Type.Elem, tt.elem)
+			}
+		})
+	}
+}
+
+func TestParseOptional(t *testing.T) {
+	m := parse(t, `[{"a": 1, "b": null, "c": true}, {"a": 2, "b": "x"}]`)
+	tests := []struct {
+		key      string
+		optional bool
+		nullable bool
+	}{
+		{"a", false, false},
+		{"b", false, true},
+		{"c", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			p := property(m, tt.key)
+			if p == nil {
+				t.Fatalf("no property %s", tt.key)
+			}
+			if p.Optional != tt.optional || p.Type.Optional != tt.optional {
+				t.Errorf("optional %v, type optional %v, want %v", p.Optional, p.Type.Optional, tt.optional)
+			}
+			if p.Type.Nullable != tt.nullable {
+				t.Errorf("nullable %v, want %v", p.Type.Nullable, tt.nullable)
+			}
+		})
+	}
+}
+
+func TestParseNestedArrays(t *testing.T) {
+	tests := []struct {
+		data  string
+		types []string
+	}{
+		{`{"v": [[1, 2], [3]]}`, []string{meta.TypeArrayArray, meta.TypeArrayInt, meta.TypeInt}},
+		{`{"v": [[[1.5]], [[2]]]}`, []string{meta.TypeArrayArray, meta.TypeArrayArray, meta.TypeArrayFloat, meta.TypeFloat}},
+		{`{"v": [[1], 2]}`, []string{meta.TypeArray, meta.TypeNull}},
+		{`{"v": []}`, []string{meta.TypeArray, meta.TypeNull}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			p := property(parse(t, tt.data), "v")
+			if p == nil {
+				t.Fatal("no property v")
+			}
+			var types []string
+			for typ := &p.Type; typ != nil; typ = typ.Elem {
+				types = append(types, typ.Value)
+			}
+			if strings.Join(types, ",") != strings.Join(tt.types, ",") {
+				t.Errorf("types %v, want %v", types, tt.types)
+			}
+		})
+	}
+}
+
+func TestParsePaths(t *testing.T) {
+	m := parse(t, `{"first name": "a", "addresses": [{"city": "x"}], "geo": {"lat": 1.5}}`)
+	tests := []struct {
+		path string
+		want meta.Path
+	}{
+		{"first name", `$["first name"]`},
+		{"addresses", "$.addresses"},
+		{"addresses.city", "$.addresses[*].city"},
+		{"geo.lat", "$.geo.lat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			obj := m
+			var p *meta.Property
+			for _, key := range strings.Split(tt.path, ".") {
+				if p != nil {
+					obj = p.Nest
+				}
+				if p = property(obj, key); p == nil {
+					t.Fatalf("no property %s", key)
+				}
+			}
+			if p.Path != tt.want {
+				t.Errorf("path %s, want %s", p.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRootPath(t *testing.T) {
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"/data/user", "name"},
+		{"$.data.user", "name"},
+		{"$.data.items[*]", "sku"},
+	}
+	data := `{"data": {"user": {"name": "a"}, "items": [{"sku": "x"}]}}`
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m := parse(t, data, WithRootPath(tt.path))
+			if property(m, tt.key) == nil {
+				t.Errorf("no property %s", tt.key)
+			}
+		})
+	}
+}
+
+func TestParseRecursion(t *testing.T) {
+	data := `{"comments": [{"text": "a", "replies": [{"text": "b", "replies": []}]}]}`
+	comments := property(parse(t, data, WithRecursion(true)), "comments")
+	if comments == nil || comments.Nest == nil {
+		t.Fatal("no comments class")
+	}
+	replies := property(comments.Nest, "replies")
+	if replies == nil {
+		t.Fatal("no replies")
+	}
+	if replies.Nest != nil || replies.Ref != comments.Nest || !replies.Type.Recursive {
+		t.Errorf("replies don't refer to the comments class")
+	}
+
+	comments = property(parse(t, data, WithRecursion(false)), "comments")
+	if replies := property(comments.Nest, "replies"); replies.Ref != nil {
+		t.Errorf("replies refer to a class without recursion")
+	}
+}
+
+func TestParseVariants(t *testing.T) {
+	data := `{"events": [{"type": "click", "x": 1, "at": 1}, {"type": "view", "url": "/", "at": 2}]}`
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"discriminator", []Option{WithDiscriminators("type")}},
+		{"detected", []Option{WithDiscriminatorDetection(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := property(parse(t, data, tt.opts...), "events")
+			if events == nil || events.Nest == nil {
+				t.Fatal("no events class")
+			}
+			if events.Nest.Discriminator != "type" {
+				t.Errorf("discriminator %s, want type", events.Nest.Discriminator)
+			}
+			var values []string
+			for _, variant := range events.Nest.Variants {
+				values = append(values, variant.DiscriminatorValue)
+			}
+			if strings.Join(values, ",") != "click,view" {
+				t.Errorf("variants %v, want [click view]", values)
+			}
+			// the common properties stay in the base
+			if property(events.Nest, "at") == nil {
+				t.Error("no common property at")
+			}
+		})
+	}
+}
+
+func TestParseVariantsShareClasses(t *testing.T) {
+	m := parse(t, `{"events": [
+		{"type": "click", "x": 1, "meta": {"id": 1}},
+		{"type": "view", "url": "/", "meta": {"id": 2}},
+		{"type": "scroll", "y": 2}
+	]}`, WithDiscriminators("type"))
+
+	events := property(m, "events")
+	if events == nil || events.Nest == nil {
+		t.Fatal("no events class")
+	}
+	if len(events.Nest.Variants) != 3 {
+		t.Fatalf("%d variants, want 3", len(events.Nest.Variants))
+	}
+
+	// meta of click and view is defined once and referred by the other variant
+	var nests, refs int
+	for _, variant := range events.Nest.Variants {
+		if p := property(variant, "meta"); p != nil {
+			if p.Nest != nil {
+				nests++
+			}
+			if p.Ref != nil {
+				refs++
+			}
+		}
+	}
+	if nests != 1 || refs != 1 {
+		t.Errorf("meta is nested %d and referred %d times, want 1 and 1", nests, refs)
+	}
+}
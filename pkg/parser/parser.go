@@ -8,4 +8,78 @@ type Parser interface {
 
 type Option func(opts *options) error
 
-type options struct{}
+// WithMapMinKeys treats objects with at least n keys and homogeneous values as maps, 0 disables the heuristic
+func WithMapMinKeys(n int) Option {
+	return func(opts *options) error {
+		opts.mapMinKeys = n
+		return nil
+	}
+}
+
+// WithMapKeys treats objects under the given property keys as maps
+func WithMapKeys(keys ...string) Option {
+	return func(opts *options) error {
+		opts.mapKeys = append(opts.mapKeys, keys...)
+		return nil
+	}
+}
+
+// WithRootPath selects the node of the data that becomes the root object, by a JSON pointer, ex. /data/user,
+// or by a JSON path, ex. $.data.items[*], where [*] treats the array elements as the root.
+// The paths of the parsed meta are relative to the selected node.
+func WithRootPath(path string) Option {
+	return func(opts *options) error {
+		segments, err := splitRootPath(path)
+		if err != nil {
+			return err
+		}
+		opts.rootPath = segments
+		return nil
+	}
+}
+
+// WithRecursion makes the objects repeating the shape of an ancestor under the same key references to
+// the ancestor, ex. replies of comments or children of categories, so the structure is recursive
+func WithRecursion(enable bool) Option {
+	return func(opts *options) error {
+		opts.recursion = enable
+		return nil
+	}
+}
+
+// WithDiscriminators splits arrays of objects into variants by the first of the keys having different
+// string values, ex. type of [{"type": "click", "x": 1}, {"type": "view", "url": "/"}]
+func WithDiscriminators(keys ...string) Option {
+	return func(opts *options) error {
+		opts.discriminators = append(opts.discriminators, keys...)
+		return nil
+	}
+}
+
+// WithDiscriminatorDetection splits arrays of objects into variants by a detected discriminator,
+// a string property of all objects whose values group the objects of different shapes
+func WithDiscriminatorDetection(detect bool) Option {
+	return func(opts *options) error {
+		opts.detectDiscriminator = detect
+		return nil
+	}
+}
+
+type options struct {
+	mapKeys    []string
+	mapMinKeys int
+	rootPath   []string
+	recursion  bool
+
+	discriminators      []string
+	detectDiscriminator bool
+}
+
+func (o *options) apply(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
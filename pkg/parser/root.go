@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nikitaksv/dynjson"
+	"github.com/pkg/errors"
+)
+
+// splitRootPath splits a JSON pointer, ex. /data/user, or a JSON path, ex. $.data.items[*], into
+// member keys and array indexes. The * segment selects all elements of an array.
+func splitRootPath(path string) ([]string, error) {
+	if path == "" || path == "/" || path == "$" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(path, "/") {
+		segments := strings.Split(path[1:], "/")
+		for i, segment := range segments {
+			segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+		}
+		return segments, nil
+	}
+
+	p := strings.TrimPrefix(path, "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+
+	var segments []string
+	for p != "" {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			if end == 0 {
+				return nil, errors.Errorf("empty key in root path \"%s\"", path)
+			}
+			segments = append(segments, p[1:end+1])
+			p = p[end+1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if strings.HasPrefix(p, `["`) {
+				// quoted key may contain ']'
+				end = strings.Index(p, `"]`) + 1
+			}
+			if end <= 0 {
+				return nil, errors.Errorf("unclosed bracket in root path \"%s\"", path)
+			}
+			segment := p[1:end]
+			if strings.HasPrefix(segment, `"`) {
+				var err error
+				if segment, err = strconv.Unquote(segment); err != nil {
+					return nil, errors.WithMessagef(err, "invalid quoted key in root path \"%s\"", path)
+				}
+			}
+			segments = append(segments, segment)
+			p = p[end+1:]
+		default:
+			return nil, errors.Errorf("unexpected \"%c\" in root path \"%s\"", p[0], path)
+		}
+	}
+	return segments, nil
+}
+
+// selectRoot returns the value at the segments of the root path. The * segment selects all elements of
+// arrays, then the result is the array of selected values.
+func selectRoot(value interface{}, segments []string) (interface{}, error) {
+	values := []interface{}{value}
+	all := false
+	for i, segment := range segments {
+		var selected []interface{}
+		for _, v := range values {
+			switch vType := v.(type) {
+			case *dynjson.Object:
+				if property, ok := vType.GetProperty(segment); ok {
+					selected = append(selected, property.Value)
+				}
+			case *dynjson.Array:
+				if segment == "*" {
+					all = true
+					selected = append(selected, vType.Elements...)
+				} else if idx, err := strconv.Atoi(segment); err == nil && idx >= 0 && idx < len(vType.Elements) {
+					selected = append(selected, vType.Elements[idx])
+				}
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errors.Errorf("root path segment \"%s\" selects nothing", strings.Join(segments[:i+1], "/"))
+		}
+		values = selected
+	}
+
+	if all {
+		return &dynjson.Array{Elements: values}, nil
+	}
+	return values[0], nil
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"github.com/nikitaksv/dynjson"
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+// knownDiscriminators are keys checked first by the discriminator detection
+var knownDiscriminators = []string{"type", "kind", "@type", "__typename", "_type", "event", "eventType", "event_type"}
+
+// parseVariants parses the array of objects told apart by a discriminator into the meta of their common
+// properties and a variant meta per discriminator value. It reports false if the array isn't polymorphic.
+func (p *parserJSON) parseVariants(obj *meta.Meta, arr *dynjson.Array, options *options, ancestors []ancestor) bool {
+	if len(options.discriminators) == 0 && !options.detectDiscriminator {
+		return false
+	}
+
+	objects := make([]*dynjson.Object, 0, len(arr.Elements))
+	for _, v := range arr.Elements {
+		switch vType := v.(type) {
+		case *dynjson.Object:
+			objects = append(objects, vType)
+		case nil:
+		default:
+			return false
+		}
+	}
+
+	key, values, groups := discriminate(objects, options)
+	if key == "" {
+		return false
+	}
+
+	samples := make([]*dynjson.Object, 0, len(values))
+	for _, value := range values {
+		samples = append(samples, p.mergeMap(groups[value]...))
+	}
+
+	// the base holds the properties of all variants, shared holds the other properties of several variants
+	merged := p.mergeMap(samples...)
+	common := &dynjson.Object{}
+	shared := &dynjson.Object{}
+	for _, property := range merged.Properties {
+		count := 0
+		for _, sample := range samples {
+			if _, ok := sample.GetProperty(property.Key); ok {
+				count++
+			}
+		}
+		switch {
+		case count == len(samples):
+			common.Properties = append(common.Properties, property)
+		case count > 1:
+			shared.Properties = append(shared.Properties, property)
+		}
+	}
+	if stats, ok := p.samples[merged]; ok {
+		p.samples[common] = stats
+		p.samples[shared] = stats
+	}
+	p.parseMap(obj, common, options, ancestors)
+	obj.Discriminator = meta.Key(key)
+
+	// nested classes of properties of several variants are parsed once from the samples of all variants
+	sharedObj := &meta.Meta{Key: obj.Key, OriginalKey: obj.OriginalKey, Path: obj.Path, Type: obj.Type.Clone()}
+	p.parseMap(sharedObj, shared, options, ancestors)
+	classes := append(append([]*meta.Property{}, obj.Properties...), sharedObj.Properties...)
+	defined := map[meta.Key]bool{}
+	for _, property := range obj.Properties {
+		defined[property.OriginalKey] = true
+	}
+
+	for i, value := range values {
+		// ex. click_events, singularized to click_event by formatter
+		variantKey := meta.Key(value)
+		if obj.Key != "" {
+			variantKey += "_" + obj.Key
+		}
+		variant := &meta.Meta{
+			Key:                variantKey,
+			OriginalKey:        obj.OriginalKey,
+			Path:               obj.Path.Filter(key, value),
+			Type:               obj.Type.Clone(),
+			Discriminator:      obj.Discriminator,
+			DiscriminatorValue: value,
+		}
+		p.parseMap(variant, samples[i], options, ancestors)
+
+		// properties of the base and of other variants refer to the same classes, so that they're defined once
+		for _, property := range variant.Properties {
+			for _, class := range classes {
+				if class.OriginalKey != property.OriginalKey || class.Nest == nil && class.Ref == nil {
+					continue
+				}
+				property.Nest = nil
+				property.Ref = class.Nest
+				if property.Ref == nil {
+					property.Ref = class.Ref
+				} else if !defined[class.OriginalKey] {
+					// the first variant defines the shared class
+					property.Nest, property.Ref = class.Nest, nil
+					defined[class.OriginalKey] = true
+				}
+				property.Type = class.Type.Clone()
+			}
+		}
+		obj.Variants = append(obj.Variants, variant)
+	}
+
+	return true
+}
+
+// discriminate returns the discriminator key of the objects, its values in order of occurrence and
+// the objects of every value. The key is empty if the objects have no discriminator.
+func discriminate(objects []*dynjson.Object, options *options) (string, []string, map[string][]*dynjson.Object) {
+	if len(objects) < 2 {
+		return "", nil, nil
+	}
+
+	for _, key := range options.discriminators {
+		if values, groups := groupBy(objects, key); len(values) > 1 {
+			return key, values, groups
+		}
+	}
+	if !options.detectDiscriminator {
+		return "", nil, nil
+	}
+
+	candidates := append([]string{}, knownDiscriminators...)
+	known := len(candidates)
+	for _, property := range objects[0].Properties {
+		candidates = append(candidates, property.Key)
+	}
+	for i, key := range candidates {
+		values, groups := groupBy(objects, key)
+		if len(values) < 2 {
+			continue
+		}
+		// unknown keys must repeat their values, otherwise they are rather names or ids
+		if i >= known && len(values) == len(objects) {
+			continue
+		}
+		if distinctShapes(groups) {
+			return key, values, groups
+		}
+	}
+	return "", nil, nil
+}
+
+// groupBy groups the objects by the string value of the key, it returns no values if any object lacks it
+func groupBy(objects []*dynjson.Object, key string) ([]string, map[string][]*dynjson.Object) {
+	var values []string
+	groups := map[string][]*dynjson.Object{}
+	for _, obj := range objects {
+		property, ok := obj.GetProperty(key)
+		if !ok {
+			return nil, nil
+		}
+		value, ok := property.Value.(string)
+		if !ok || value == "" {
+			return nil, nil
+		}
+		if _, ok := groups[value]; !ok {
+			values = append(values, value)
+		}
+		groups[value] = append(groups[value], obj)
+	}
+	return values, groups
+}
+
+// distinctShapes reports whether the groups differ by their keys
+func distinctShapes(groups map[string][]*dynjson.Object) bool {
+	var first map[string]bool
+	for _, objects := range groups {
+		keys := map[string]bool{}
+		for _, obj := range objects {
+			for _, property := range obj.Properties {
+				keys[property.Key] = true
+			}
+		}
+		if first == nil {
+			first = keys
+			continue
+		}
+		if len(keys) != len(first) {
+			return true
+		}
+		for key := range keys {
+			if !first[key] {
+				return true
+			}
+		}
+	}
+	return false
+}
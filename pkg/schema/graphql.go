@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+)
+
+// graphQLScalars are the custom scalars of meta types, declared if they're used
+var graphQLScalars = []string{"Int64", "BigInt", "Date", "Time", "DateTime", "Duration", "JSON"}
+
+// GraphQL renders GraphQL SDL object types of the meta classes, a polymorphic class is a union of its variants.
+// Classes without properties are JSON, integers are Int64 as Int of GraphQL is 32-bit
+func GraphQL(m *meta.Meta) ([]byte, error) {
+	if err := checkClassNames(m); err != nil {
+		return nil, err
+	}
+
+	scalars := map[string]bool{}
+	body := &strings.Builder{}
+	for _, class := range m.Classes() {
+		if isEmpty(class) {
+			continue
+		}
+		body.WriteString("\n")
+		if class.IsPolymorphic() {
+			names := make([]string, 0, len(class.Variants))
+			for _, variant := range class.Variants {
+				names = append(names, string(variant.Key))
+			}
+			fmt.Fprintf(body, "union %s = %s\n", class.Key, strings.Join(names, " | "))
+			continue
+		}
+
+		fmt.Fprintf(body, "type %s {\n", class.Key)
+		for _, property := range class.Properties {
+			typ := graphQLType(property.Type, target(property), scalars)
+			if !property.Optional && !property.Type.Nullable && !property.Type.IsNull() {
+				typ += "!"
+			}
+			fmt.Fprintf(body, "  %s: %s\n", property.Key, typ)
+		}
+		body.WriteString("}\n")
+	}
+
+	b := &strings.Builder{}
+	for _, scalar := range graphQLScalars {
+		if scalars[scalar] {
+			fmt.Fprintf(b, "scalar %s\n", scalar)
+		}
+	}
+	out := strings.TrimPrefix(b.String()+body.String(), "\n")
+	return []byte(out), nil
+}
+
+// graphQLType returns the nullable type of the meta type, class is the class of objects of the type
+func graphQLType(t meta.Type, class *meta.Meta, scalars map[string]bool) string {
+	switch {
+	case t.IsObject() && class != nil && !isEmpty(class):
+		return string(class.Key)
+	case t.IsObject() && class != nil, t.IsInline(), t.IsMap(), t.Value == meta.TypeArray:
+		scalars["JSON"] = true
+		return "JSON"
+	case t.IsArray() && t.Elem != nil:
+		elem := graphQLType(*t.Elem, class, scalars)
+		if !t.Elem.Nullable && !t.Elem.IsNull() {
+			elem += "!"
+		}
+		return "[" + elem + "]"
+	}
+
+	var scalar string
+	switch t.Value {
+	case meta.TypeFloat:
+		return "Float"
+	case meta.TypeBool:
+		return "Boolean"
+	case meta.TypeString:
+		return "String"
+	case "uuid":
+		return "ID"
+	case meta.TypeInt:
+		scalar = "Int64"
+	case meta.TypeBigInt:
+		scalar = "BigInt"
+	case meta.TypeDate:
+		scalar = "Date"
+	case meta.TypeTime:
+		scalar = "Time"
+	case meta.TypeDateTime:
+		scalar = "DateTime"
+	case meta.TypeDuration:
+		scalar = "Duration"
+	case meta.TypeNull, meta.TypeObject:
+		scalar = "JSON"
+	default:
+		// custom types of overrides
+		return "String"
+	}
+	scalars[scalar] = true
+	return scalar
+}
+
+// isEmpty reports whether the class has no fields, an empty object type is invalid SDL
+func isEmpty(class *meta.Meta) bool {
+	return len(class.Properties) == 0 && !class.IsPolymorphic()
+}
@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// stringFormats are formats of JSON Schema for custom types set by overrides, ex. uuid
+var stringFormats = map[string]bool{
+	"uuid": true, "email": true, "uri": true, "hostname": true, "ipv4": true, "ipv6": true,
+}
+
+// builder builds JSON Schemas of classes, which refer to each other by ref
+type builder struct {
+	ref func(class *meta.Meta) string
+	// openAPI adds the formats of numbers and the discriminators of OpenAPI
+	openAPI bool
+}
+
+// JSONSchema renders the JSON Schema (draft 2020-12) of the meta, nested classes are defined in $defs
+func JSONSchema(m *meta.Meta) ([]byte, error) {
+	if err := checkClassNames(m); err != nil {
+		return nil, err
+	}
+
+	b := &builder{ref: func(class *meta.Meta) string {
+		if class == m {
+			return "#"
+		}
+		// JSON pointer escaping
+		return "#/$defs/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(string(class.Key))
+	}}
+
+	s := newObject().
+		set("$schema", "https://json-schema.org/draft/2020-12/schema").
+		set("title", string(m.Key))
+	root := b.class(m)
+	for _, key := range root.keys {
+		s.set(key, root.values[key])
+	}
+	defs := newObject()
+	for _, class := range m.Classes()[1:] {
+		defs.set(string(class.Key), b.class(class))
+	}
+	if len(defs.keys) > 0 {
+		s.set("$defs", defs)
+	}
+
+	bs, err := marshalJSON(s)
+	if err != nil {
+		return nil, errors.WithMessage(err, "can't marshal JSON Schema")
+	}
+	out := &bytes.Buffer{}
+	if err := json.Indent(out, bs, "", "  "); err != nil {
+		return nil, errors.WithMessage(err, "can't marshal JSON Schema")
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// OpenAPI renders the components.schemas of OpenAPI 3.1 in YAML, a schema per class
+func OpenAPI(m *meta.Meta) ([]byte, error) {
+	if err := checkClassNames(m); err != nil {
+		return nil, err
+	}
+
+	b := &builder{openAPI: true, ref: func(class *meta.Meta) string {
+		return "#/components/schemas/" + string(class.Key)
+	}}
+	schemas := newObject()
+	for _, class := range m.Classes() {
+		schemas.set(string(class.Key), b.class(class))
+	}
+
+	out := &bytes.Buffer{}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(newObject().set("components", newObject().set("schemas", schemas))); err != nil {
+		return nil, errors.WithMessage(err, "can't marshal OpenAPI components")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.WithMessage(err, "can't marshal OpenAPI components")
+	}
+	return out.Bytes(), nil
+}
+
+func (b *builder) class(class *meta.Meta) *object {
+	if class.IsPolymorphic() {
+		oneOf := make([]interface{}, 0, len(class.Variants))
+		mapping := newObject()
+		for _, variant := range class.Variants {
+			oneOf = append(oneOf, newObject().set("$ref", b.ref(variant)))
+			mapping.set(variant.DiscriminatorValue, b.ref(variant))
+		}
+		s := newObject().set("oneOf", oneOf)
+		if b.openAPI {
+			s.set("discriminator", newObject().
+				set("propertyName", string(class.Discriminator)).
+				set("mapping", mapping))
+		}
+		return s
+	}
+
+	properties := newObject()
+	required := make([]string, 0, len(class.Properties))
+	for _, property := range class.Properties {
+		if isDiscriminator(class, property) {
+			properties.set(string(property.OriginalKey), newObject().
+				set("type", "string").
+				set("const", class.DiscriminatorValue))
+		} else {
+			properties.set(string(property.OriginalKey), b.typ(property.Type, target(property)))
+		}
+		if !property.Optional {
+			required = append(required, string(property.OriginalKey))
+		}
+	}
+
+	s := newObject().set("type", "object").set("properties", properties)
+	if len(required) > 0 {
+		s.set("required", required)
+	}
+	return s
+}
+
+// typ returns the schema of the type, class is the class of objects of the type
+func (b *builder) typ(t meta.Type, class *meta.Meta) *object {
+	var s *object
+	switch {
+	case t.IsInline():
+		s = b.class(t.Inline)
+	case t.IsObject() && class != nil:
+		s = newObject().set("$ref", b.ref(class))
+	case t.IsMap():
+		s = newObject().set("type", "object").set("additionalProperties", b.elem(t, class))
+	case t.IsArray():
+		s = newObject().set("type", "array").set("items", b.elem(t, class))
+	default:
+		s = b.scalar(t.Value)
+	}
+
+	if !t.Nullable || len(s.keys) == 0 {
+		return s
+	}
+	if typ, ok := s.values["type"].(string); ok {
+		return s.set("type", []string{typ, "null"})
+	}
+	return newObject().set("anyOf", []interface{}{s, newObject().set("type", "null")})
+}
+
+// elem returns the schema of the elements of an array or a map, any value if they're unknown
+func (b *builder) elem(t meta.Type, class *meta.Meta) *object {
+	if t.Elem == nil || t.Value == meta.TypeArray {
+		return newObject()
+	}
+	return b.typ(*t.Elem, class)
+}
+
+func (b *builder) scalar(value string) *object {
+	s := newObject()
+	switch value {
+	case meta.TypeNull:
+		s.set("type", "null")
+	case meta.TypeInt:
+		s.set("type", "integer")
+		if b.openAPI {
+			s.set("format", "int64")
+		}
+	case meta.TypeBigInt:
+		s.set("type", "integer")
+	case meta.TypeFloat:
+		s.set("type", "number")
+		if b.openAPI {
+			s.set("format", "double")
+		}
+	case meta.TypeBool:
+		s.set("type", "boolean")
+	case meta.TypeString:
+		s.set("type", "string")
+	case meta.TypeDate:
+		s.set("type", "string").set("format", "date")
+	case meta.TypeTime:
+		s.set("type", "string").set("format", "time")
+	case meta.TypeDateTime:
+		s.set("type", "string").set("format", "date-time")
+	case meta.TypeDuration:
+		s.set("type", "string").set("format", "duration")
+	default:
+		// custom types of overrides
+		if stringFormats[value] {
+			s.set("type", "string").set("format", value)
+		}
+	}
+	return s
+}
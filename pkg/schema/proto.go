@@ -0,0 +1,247 @@
+package schema
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+)
+
+// ProtoFieldAttribute is the property attribute pinning its field number, ex. set by overrides
+const ProtoFieldAttribute = "protoField"
+
+// protoHashedFields is the range of hashed field numbers, their tags take up to 3 bytes
+const protoHashedFields = 1<<18 - 1
+
+const (
+	protoMaxField       = 536870911
+	protoReservedFirst  = 19000
+	protoReservedLast   = 19999
+	protoTimestamp      = "google.protobuf.Timestamp"
+	protoValue          = "google.protobuf.Value"
+	protoStruct         = "google.protobuf.Struct"
+	protoListValue      = "google.protobuf.ListValue"
+	protoTimestampProto = "google/protobuf/timestamp.proto"
+	protoStructProto    = "google/protobuf/struct.proto"
+)
+
+// Proto renders proto3 messages of the meta classes, variants of a polymorphic class are a oneof.
+// A field number is a hash of the data key of the field, of the discriminator value of a variant, so it doesn't
+// depend on the order of keys in samples, and adding or removing fields doesn't renumber the others.
+// Hash collisions move the key sorted later to the next free number, pin the numbers by ProtoFieldAttribute
+// to keep them regardless of other fields
+func Proto(m *meta.Meta, pkg string) ([]byte, error) {
+	if err := checkClassNames(m); err != nil {
+		return nil, err
+	}
+
+	imports := map[string]bool{}
+	body := &strings.Builder{}
+	for _, class := range m.Classes() {
+		body.WriteString("\n")
+		if err := writeMessage(body, class, imports); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("syntax = \"proto3\";\n")
+	if pkg != "" {
+		fmt.Fprintf(b, "\npackage %s;\n", pkg)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+		names := make([]string, 0, len(imports))
+		for name := range imports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(b, "import %q;\n", name)
+		}
+	}
+	b.WriteString(body.String())
+	return []byte(b.String()), nil
+}
+
+func writeMessage(b *strings.Builder, class *meta.Meta, imports map[string]bool) error {
+	fmt.Fprintf(b, "message %s {\n", class.Key)
+	if class.IsPolymorphic() {
+		fmt.Fprintf(b, "  oneof %s {\n", class.Discriminator.SnakeCase())
+		values := make([]string, 0, len(class.Variants))
+		for _, variant := range class.Variants {
+			values = append(values, variant.DiscriminatorValue)
+		}
+		numbers := hashedNumbers(values, map[int]bool{})
+		for i, variant := range class.Variants {
+			fmt.Fprintf(b, "    %s %s = %d;\n", variant.Key, variant.Key.SnakeCase(), numbers[i])
+		}
+		b.WriteString("  }\n}\n")
+		return nil
+	}
+
+	numbers, err := fieldNumbers(class)
+	if err != nil {
+		return err
+	}
+	for i, property := range class.Properties {
+		typ := protoType(property.Type, target(property), imports)
+		if (property.Optional || property.Type.Nullable) && protoScalar(property.Type) {
+			typ = "optional " + typ
+		}
+		fmt.Fprintf(b, "  %s %s = %d", typ, property.Key, numbers[i])
+		if jsonName := string(property.OriginalKey); jsonName != protoJSONName(string(property.Key)) {
+			fmt.Fprintf(b, " [json_name = %q]", jsonName)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return nil
+}
+
+// fieldNumbers returns the field numbers of the class properties
+func fieldNumbers(class *meta.Meta) ([]int, error) {
+	numbers := make([]int, len(class.Properties))
+	used := map[int]bool{}
+	for i, property := range class.Properties {
+		value, ok := property.Attributes[ProtoFieldAttribute]
+		if !ok {
+			continue
+		}
+		number, ok := toInt(value)
+		if !ok || number < 1 || number > protoMaxField || number >= protoReservedFirst && number <= protoReservedLast {
+			return nil, errors.Errorf("invalid %s %v of %s", ProtoFieldAttribute, value, property.Path)
+		}
+		if used[number] {
+			return nil, errors.Errorf("%s %d of %s is used by another field", ProtoFieldAttribute, number, property.Path)
+		}
+		used[number] = true
+		numbers[i] = number
+	}
+
+	var keys []string
+	var unpinned []int
+	for i, property := range class.Properties {
+		if numbers[i] == 0 {
+			keys = append(keys, string(property.OriginalKey))
+			unpinned = append(unpinned, i)
+		}
+	}
+	for i, number := range hashedNumbers(keys, used) {
+		numbers[unpinned[i]] = number
+	}
+	return numbers, nil
+}
+
+// hashedNumbers returns field numbers hashed from the keys, which don't use the used numbers.
+// A key colliding with a key sorted before it takes the next free number
+func hashedNumbers(keys []string, used map[int]bool) []int {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+
+	numbers := make([]int, len(keys))
+	for _, i := range order {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(keys[i]))
+		number := int(h.Sum32()%protoHashedFields) + 1
+		for used[number] || number >= protoReservedFirst && number <= protoReservedLast {
+			number = number%protoHashedFields + 1
+		}
+		used[number] = true
+		numbers[i] = number
+	}
+	return numbers
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), float64(int(n)) == n
+	default:
+		return 0, false
+	}
+}
+
+// protoType returns the field type of the meta type, class is the class of objects of the type
+func protoType(t meta.Type, class *meta.Meta, imports map[string]bool) string {
+	switch {
+	case t.IsInline():
+		imports[protoStructProto] = true
+		return protoStruct
+	case t.IsObject() && class != nil:
+		return string(class.Key)
+	case t.IsMap():
+		if t.Elem == nil || t.Elem.IsArray() || t.Elem.IsMap() || t.Elem.IsNull() {
+			imports[protoStructProto] = true
+			return "map<string, " + protoValue + ">"
+		}
+		return "map<string, " + protoType(*t.Elem, class, imports) + ">"
+	case t.Value == meta.TypeArray:
+		imports[protoStructProto] = true
+		return protoListValue
+	case t.IsArray():
+		// arrays of arrays and of maps are lists of values
+		if t.Elem == nil || t.Elem.IsArray() || t.Elem.IsMap() {
+			imports[protoStructProto] = true
+			return "repeated " + protoListValue
+		}
+		return "repeated " + protoType(*t.Elem, class, imports)
+	}
+
+	switch t.Value {
+	case meta.TypeInt:
+		return "int64"
+	case meta.TypeFloat:
+		return "double"
+	case meta.TypeBool:
+		return "bool"
+	case meta.TypeDateTime:
+		imports[protoTimestampProto] = true
+		return protoTimestamp
+	case meta.TypeNull, meta.TypeObject:
+		imports[protoStructProto] = true
+		return protoValue
+	default:
+		// big integers, dates without time, durations and custom types keep their text
+		return "string"
+	}
+}
+
+// protoScalar reports whether the field of the type may have the optional label
+func protoScalar(t meta.Type) bool {
+	if t.IsArray() || t.IsMap() || t.IsObject() || t.IsInline() {
+		return false
+	}
+	return t.Value != meta.TypeNull && t.Value != meta.TypeDateTime
+}
+
+// protoJSONName returns the default JSON name of the field, ex. created_at is createdAt
+func protoJSONName(name string) string {
+	b := &strings.Builder{}
+	upper := false
+	for _, c := range name {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
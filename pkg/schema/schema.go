@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// object is a JSON object keeping the order of its keys
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: map[string]interface{}{}}
+}
+
+func (o *object) set(key string, value interface{}) *object {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+	return o
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshalJSON(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (o *object) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range o.keys {
+		value := &yaml.Node{}
+		if err := value.Encode(o.values[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+	return node, nil
+}
+
+// marshalJSON marshals the value without escaping HTML characters of keys, ex. <
+func marshalJSON(v interface{}) ([]byte, error) {
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// checkClassNames returns an error if distinct classes of the meta share a name, their references would be ambiguous
+func checkClassNames(m *meta.Meta) error {
+	names := map[meta.Key]*meta.Meta{}
+	for _, class := range m.Classes() {
+		if other, ok := names[class.Key]; ok && other != class {
+			return errors.Errorf("class name \"%s\" is used by several classes, ex. %s and %s", class.Key, other.Path, class.Path)
+		}
+		names[class.Key] = class
+	}
+	return nil
+}
+
+// target returns the class of the object property or of its elements
+func target(property *meta.Property) *meta.Meta {
+	if property.Nest != nil {
+		return property.Nest
+	}
+	return property.Ref
+}
+
+// isDiscriminator reports whether the property is the discriminator of the variant
+func isDiscriminator(class *meta.Meta, property *meta.Property) bool {
+	return class.DiscriminatorValue != "" && property.OriginalKey == class.Discriminator
+}
@@ -0,0 +1,287 @@
+package schema
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nikitaksv/gendata/pkg/formatter"
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/nikitaksv/gendata/pkg/parser"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+func className(key meta.Key) (string, error) {
+	name := strings.ReplaceAll(key.String(), "_", "")
+	if name == "" {
+		return "", nil
+	}
+	return strings.ToUpper(name[:1]) + name[1:], nil
+}
+
+func format(t *testing.T, data string) *meta.Meta {
+	t.Helper()
+	p, err := parser.NewParserJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := p.Parse([]byte(data), parser.WithDiscriminators("type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = formatter.NewFormatter().Format(m,
+		formatter.WithRootClassName("Root"),
+		formatter.WithClassNameFormatter(className),
+		formatter.WithPropertyNameFormatter(func(key meta.Key) (string, error) { return key.String(), nil }),
+		formatter.WithTypeNameFormatter(&meta.TypeFormatters{
+			Type: func(t meta.Type) (string, error) { return t.Value, nil },
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+const data = `{
+	"id": 1,
+	"big": 9007199254740993,
+	"name": "a",
+	"unknown": null,
+	"empty": {},
+	"tags": ["a"],
+	"address": {"city": "x"},
+	"events": [
+		{"type": "click", "x": 1},
+		{"type": "view", "url": "/"}
+	],
+	"items": [{"sku": "a", "qty": 1}, {"sku": "b"}]
+}`
+
+func TestJSONSchema(t *testing.T) {
+	b, err := JSONSchema(format(t, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b)
+	}
+	defs, _ := doc["$defs"].(map[string]interface{})
+	for _, class := range []string{"Address", "Items", "Events"} {
+		if _, ok := defs[class]; !ok {
+			t.Errorf("no definition of %s", class)
+		}
+	}
+	// every reference has a definition
+	for _, ref := range regexp.MustCompile(`"#/\$defs/(\w+)"`).FindAllStringSubmatch(string(b), -1) {
+		if _, ok := defs[ref[1]]; !ok {
+			t.Errorf("undefined reference %s", ref[1])
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	b, err := OpenAPI(format(t, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := struct {
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, b)
+	}
+	for _, ref := range regexp.MustCompile(`#/components/schemas/(\w+)`).FindAllStringSubmatch(string(b), -1) {
+		if _, ok := doc.Components.Schemas[ref[1]]; !ok {
+			t.Errorf("undefined reference %s", ref[1])
+		}
+	}
+}
+
+var protoFieldRe = regexp.MustCompile(`(?m)^\s+(?:optional |repeated )?[\w.]+ (\w+) = (\d+)`)
+
+func protoFields(t *testing.T, data string) map[string]string {
+	t.Helper()
+	b, err := Proto(format(t, data), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{}
+	for _, field := range protoFieldRe.FindAllStringSubmatch(string(b), -1) {
+		fields[field[1]] = field[2]
+	}
+	return fields
+}
+
+func TestProtoFieldNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"order of keys", `{"a": 1, "b": "x", "c": true}`, `{"c": true, "b": "x", "a": 1}`},
+		{"added key", `{"a": 1, "b": "x"}`, `{"a": 1, "new": 2, "b": "x"}`},
+		{"removed key", `{"a": 1, "b": "x", "c": true}`, `{"a": 1, "b": "x"}`},
+		{
+			"order of variants",
+			`{"events": [{"type": "click", "x": 1}, {"type": "view", "url": "/"}]}`,
+			`{"events": [{"type": "view", "url": "/"}, {"type": "click", "x": 1}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := protoFields(t, tt.a), protoFields(t, tt.b)
+			if len(a) == 0 {
+				t.Fatal("no fields")
+			}
+			for field, number := range a {
+				if other, ok := b[field]; ok && other != number {
+					t.Errorf("field %s is %s and %s", field, number, other)
+				}
+			}
+		})
+	}
+}
+
+func TestProtoValid(t *testing.T) {
+	b, err := Proto(format(t, data), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range regexp.MustCompile(`(?s)message \w+ \{.*?\n\}`).FindAllString(string(b), -1) {
+		used := map[string]bool{}
+		for _, field := range protoFieldRe.FindAllStringSubmatch(message, -1) {
+			if used[field[2]] {
+				t.Errorf("field number %s is used twice:\n%s", field[2], message)
+			}
+			used[field[2]] = true
+		}
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	b, err := GraphQL(format(t, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	tests := []string{
+		"type Root {",
+		"address: Address!",
+		"items: [Items!]!",
+		"id: Int64!",
+		"big: BigInt!",
+		// null values may be anything
+		"unknown: JSON\n",
+		// empty types are invalid GraphQL
+		"empty: JSON!",
+		"scalar Int64",
+		"union Events",
+	}
+	for _, want := range tests {
+		if !strings.Contains(s, want) {
+			t.Errorf("no %q in\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "type Empty") {
+		t.Errorf("empty type in\n%s", s)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	dialect := &SQLDialect{
+		Type: func(t meta.Type) (string, error) {
+			if typ, ok := map[string]string{meta.TypeInt: "bigint", meta.TypeBigInt: "numeric"}[t.Value]; ok {
+				return typ, nil
+			}
+			return "text", nil
+		},
+		Arrays:     true,
+		JSON:       "jsonb",
+		Serial:     "bigserial PRIMARY KEY",
+		SerialType: "bigint",
+		Quote:      `"`,
+	}
+	b, err := SQL(format(t, data), dialect, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	tests := []struct {
+		column string
+		want   string
+	}{
+		{"name", "name text NOT NULL"},
+		{"null typed", "unknown jsonb,"},
+		{"optional", "qty bigint,"},
+		{"nested object", "address_id bigint NOT NULL"},
+		{"array of objects", `"Root_id" bigint NOT NULL`},
+		{"variant", "url text,"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			if !strings.Contains(s, tt.want) {
+				t.Errorf("no %q in\n%s", tt.want, s)
+			}
+		})
+	}
+
+	dialect.Type = func(t meta.Type) (string, error) { return "", errors.New("no mapping") }
+	if _, err := SQL(format(t, data), dialect, false); err == nil {
+		t.Error("no error of column types")
+	}
+}
+
+func TestSQLColumnCollisions(t *testing.T) {
+	dialect := &SQLDialect{
+		Type:       func(t meta.Type) (string, error) { return "text", nil },
+		KeyType:    func(t meta.Type) (string, error) { return "varchar(255)", nil },
+		JSON:       "json",
+		Serial:     "bigint PRIMARY KEY",
+		SerialType: "bigint",
+		Quote:      "`",
+	}
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			"foreign key first",
+			`{"address": {"city": "x"}, "address_id": "a"}`,
+			[]string{"address_id bigint NOT NULL", "address_id_2 text NOT NULL", "FOREIGN KEY (address_id)"},
+		},
+		{
+			"column first",
+			`{"address_id": "a", "address": {"city": "x"}}`,
+			[]string{"address_id text NOT NULL", "`address_Address_id` bigint NOT NULL", "FOREIGN KEY (`address_Address_id`)"},
+		},
+		{
+			"nullable id",
+			`[{"id": "a"}, {"id": null}]`,
+			[]string{"id bigint PRIMARY KEY,\n  id_2 text\n"},
+		},
+		{
+			"string keys",
+			`{"id": "a", "child": {"id": "b"}, "items": [{"sku": "x"}]}`,
+			[]string{"id varchar(255) PRIMARY KEY", "child_id varchar(255) NOT NULL", "`Root_id` varchar(255) NOT NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := SQL(format(t, tt.data), dialect, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("no %q in\n%s", want, b)
+				}
+			}
+		})
+	}
+}
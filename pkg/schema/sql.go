@@ -0,0 +1,332 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nikitaksv/gendata/pkg/meta"
+	"github.com/pkg/errors"
+)
+
+// SQLDialect describes the DDL of a database
+type SQLDialect struct {
+	// Type returns the column type of scalars, its error fails the rendering
+	Type meta.TypeFormatter
+	// KeyType returns the column type of id primary keys and of foreign keys to them, Type by default,
+	// ex. varchar(255) of strings as MySQL can't index text columns
+	KeyType meta.TypeFormatter
+	// Arrays stores arrays of scalars in array columns, ex. bigint[], otherwise in child tables of values
+	Arrays bool
+	// JSON is the column type of JSON values, ex. jsonb
+	JSON string
+	// Serial is the column definition of generated primary keys, ex. bigserial PRIMARY KEY
+	Serial string
+	// SerialType is the column type of foreign keys to generated primary keys, ex. bigint
+	SerialType string
+	// Quote quotes identifiers, ex. " or `
+	Quote string
+	// AlterForeignKeys adds the foreign keys of cyclic references by ALTER TABLE, otherwise all foreign keys
+	// are in CREATE TABLE, ex. SQLite doesn't check the referred tables
+	AlterForeignKeys bool
+}
+
+var sqlIdentifierRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// sqlReserved are the common reserved words of dialects, which must be quoted
+var sqlReserved = map[string]bool{
+	"all": true, "and": true, "as": true, "by": true, "check": true, "column": true, "constraint": true,
+	"create": true, "default": true, "desc": true, "distinct": true, "from": true, "group": true, "index": true,
+	"in": true, "key": true, "limit": true, "not": true, "null": true, "on": true, "or": true, "order": true,
+	"primary": true, "references": true, "select": true, "table": true, "to": true, "union": true,
+	"unique": true, "user": true, "where": true, "with": true,
+}
+
+type sqlTable struct {
+	name        string
+	columns     []*sqlColumn
+	foreignKeys []*sqlForeignKey
+	// keyType is the column type of foreign keys to the table
+	keyType string
+}
+
+type sqlColumn struct {
+	name       string
+	definition string
+	// property is the key of the property of the column, the same property of several variants has one column
+	property string
+	// parent is the foreign key to the parent of an array property
+	parent bool
+}
+
+type sqlForeignKey struct {
+	column string
+	table  *sqlTable
+}
+
+func (t *sqlTable) column(name string) *sqlColumn {
+	for _, column := range t.columns {
+		if column.name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// addColumn adds the column of the property unless the table has it, ex. a property of several variants.
+// The column named as a column of another property gets a suffix, ex. address_id_2
+func (t *sqlTable) addColumn(name, definition, property string) *sqlColumn {
+	unique := name
+	for i := 2; t.column(unique) != nil; i++ {
+		if column := t.column(unique); column.property == property {
+			return column
+		}
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	column := &sqlColumn{name: unique, definition: definition, property: property}
+	t.columns = append(t.columns, column)
+	return column
+}
+
+// addForeignKey adds the foreign key unless the table has it, ex. a property of several variants
+func (t *sqlTable) addForeignKey(column string, table *sqlTable) {
+	for _, fk := range t.foreignKeys {
+		if fk.column == column {
+			return
+		}
+	}
+	t.foreignKeys = append(t.foreignKeys, &sqlForeignKey{column: column, table: table})
+}
+
+// SQL renders CREATE TABLE statements of the meta classes. Scalar properties are columns, nested objects are
+// child tables referred by foreign keys, arrays of objects are child tables referring to the parent and arrays
+// of scalars are array columns or child tables of values. If jsonObjects, nested objects and arrays of objects
+// are JSON columns. Variants of a polymorphic class share the table of the class.
+func SQL(m *meta.Meta, dialect *SQLDialect, jsonObjects bool) ([]byte, error) {
+	if err := checkClassNames(m); err != nil {
+		return nil, err
+	}
+
+	w := &sqlWriter{dialect: dialect, jsonObjects: jsonObjects, tables: map[*meta.Meta]*sqlTable{}}
+	variants := map[*meta.Meta]bool{}
+	for _, class := range m.Classes() {
+		for _, variant := range class.Variants {
+			variants[variant] = true
+		}
+	}
+	// nested classes of JSON columns have no tables
+	classes := []*meta.Meta{m}
+	if !jsonObjects {
+		classes = classes[:0]
+		for _, class := range m.Classes() {
+			if !variants[class] {
+				classes = append(classes, class)
+			}
+		}
+	}
+	for _, class := range classes {
+		w.table(class)
+	}
+	for _, class := range classes {
+		w.columns(class, w.tables[class], false)
+		for _, variant := range class.Variants {
+			// columns of variants are null in rows of other variants
+			w.columns(variant, w.tables[class], true)
+		}
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	return []byte(w.write()), nil
+}
+
+type sqlWriter struct {
+	dialect     *SQLDialect
+	jsonObjects bool
+	tables      map[*meta.Meta]*sqlTable
+	// order is the order of creation of tables
+	order []*sqlTable
+	// err is the first error of column types
+	err error
+}
+
+// columnType returns the column type of the scalar, the error is kept till the end of rendering
+func (w *sqlWriter) columnType(t meta.Type) string {
+	return w.format(w.dialect.Type, t)
+}
+
+// keyColumnType returns the column type of the primary key
+func (w *sqlWriter) keyColumnType(t meta.Type) string {
+	if w.dialect.KeyType == nil {
+		return w.columnType(t)
+	}
+	return w.format(w.dialect.KeyType, t)
+}
+
+func (w *sqlWriter) format(format meta.TypeFormatter, t meta.Type) string {
+	typ, err := format(t)
+	if err != nil && w.err == nil {
+		w.err = errors.WithMessagef(err, "can't format column type of \"%s\"", t.Key)
+	}
+	return typ
+}
+
+// table creates the table of the class with its primary key, the id property or a generated key
+func (w *sqlWriter) table(class *meta.Meta) *sqlTable {
+	t := &sqlTable{name: string(class.Key), keyType: w.dialect.SerialType}
+	for _, property := range class.Properties {
+		// the flattened properties keep the keys of their objects, ex. id of user_id, they aren't keys of the table
+		flattened := property.Path != class.Path.Child(string(property.OriginalKey))
+		if property.OriginalKey == "id" && !flattened && isSQLScalar(property.Type) && !property.Type.Nullable {
+			t.keyType = w.keyColumnType(property.Type)
+			t.addColumn(string(property.Key), t.keyType+" PRIMARY KEY", string(property.Key))
+		}
+	}
+	if len(t.columns) == 0 {
+		t.addColumn("id", w.dialect.Serial, "")
+	}
+	w.tables[class] = t
+	w.order = append(w.order, t)
+	return t
+}
+
+func (w *sqlWriter) columns(class *meta.Meta, t *sqlTable, nullable bool) {
+	for _, property := range class.Properties {
+		// unknown values of null samples may be anything, their columns are nullable
+		notNull := !nullable && !property.Optional && !property.Type.Nullable && !property.Type.IsNull()
+		name := string(property.Key)
+		typ := property.Type
+		child := w.tables[target(property)]
+
+		switch {
+		case isSQLScalar(typ):
+			t.addColumn(name, sqlDefinition(w.columnType(typ), notNull), name)
+		case typ.IsObject() && child != nil && !w.jsonObjects:
+			column := name + "_id"
+			if c := t.column(column); c != nil && c.property != name {
+				column = name + "_" + child.name + "_id"
+			}
+			// the first row of a table can't refer to itself
+			column = t.addColumn(column, sqlDefinition(child.keyType, notNull && child != t), name).name
+			t.addForeignKey(column, child)
+		case typ.IsArrayObject() && child != nil && !w.jsonObjects:
+			w.addParent(child, t, name)
+		case typ.IsArray() && typ.Elem != nil && isSQLScalar(*typ.Elem) && w.dialect.Arrays:
+			t.addColumn(name, sqlDefinition(w.columnType(*typ.Elem)+"[]", notNull), name)
+		case typ.IsArray() && typ.Elem != nil && isSQLScalar(*typ.Elem):
+			values := &sqlTable{name: t.name + "_" + name, keyType: w.dialect.SerialType}
+			values.addColumn("id", w.dialect.Serial, "")
+			w.addParent(values, t, name)
+			values.addColumn("value", sqlDefinition(w.columnType(*typ.Elem), !typ.Elem.Nullable), "")
+			w.order = append(w.order, values)
+		default:
+			// maps, inline objects, arrays of arrays and unknown values
+			t.addColumn(name, sqlDefinition(w.dialect.JSON, notNull), name)
+		}
+	}
+}
+
+// addParent adds the foreign key to the parent table of the array property to the child table
+func (w *sqlWriter) addParent(child, parent *sqlTable, property string) {
+	column := parent.name + "_id"
+	if child == parent {
+		column = "parent_id"
+	}
+	// the key of the parent property, keys of columns of the child properties have no dots
+	key := parent.name + "." + property
+	for _, c := range child.columns {
+		if c.property == key {
+			// the property of several variants
+			return
+		}
+	}
+	if child.column(column) != nil {
+		column = parent.name + "_" + property + "_id"
+	}
+	// a child of several parents or of itself refers to one of them
+	notNull := child != parent
+	for _, c := range child.columns {
+		if c.parent {
+			c.definition = strings.TrimSuffix(c.definition, " NOT NULL")
+			notNull = false
+		}
+	}
+	c := child.addColumn(column, sqlDefinition(parent.keyType, notNull), key)
+	c.parent = true
+	child.addForeignKey(c.name, parent)
+}
+
+func (w *sqlWriter) write() string {
+	created := map[*sqlTable]bool{}
+	visiting := map[*sqlTable]bool{}
+	var order []*sqlTable
+	var alters []string
+	var visit func(t *sqlTable)
+	visit = func(t *sqlTable) {
+		if created[t] || visiting[t] {
+			return
+		}
+		visiting[t] = true
+		for _, fk := range t.foreignKeys {
+			visit(fk.table)
+		}
+		visiting[t] = false
+		created[t] = true
+		order = append(order, t)
+	}
+	for _, t := range w.order {
+		visit(t)
+	}
+
+	b := &strings.Builder{}
+	created = map[*sqlTable]bool{}
+	for i, t := range order {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "CREATE TABLE %s (\n", w.quote(t.name))
+		lines := make([]string, 0, len(t.columns)+len(t.foreignKeys))
+		for _, column := range t.columns {
+			lines = append(lines, fmt.Sprintf("  %s %s", w.quote(column.name), column.definition))
+		}
+		for _, fk := range t.foreignKeys {
+			definition := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+				w.quote(fk.column), w.quote(fk.table.name), w.quote(fk.table.columns[0].name))
+			if w.dialect.AlterForeignKeys && fk.table != t && !created[fk.table] {
+				// the referred table of a cycle isn't created yet
+				alters = append(alters, fmt.Sprintf("ALTER TABLE %s ADD %s;\n", w.quote(t.name), definition))
+				continue
+			}
+			lines = append(lines, "  "+definition)
+		}
+		b.WriteString(strings.Join(lines, ",\n"))
+		b.WriteString("\n);\n")
+		created[t] = true
+	}
+	if len(alters) > 0 {
+		b.WriteString("\n")
+		b.WriteString(strings.Join(alters, ""))
+	}
+	return b.String()
+}
+
+func (w *sqlWriter) quote(name string) string {
+	if sqlIdentifierRe.MatchString(name) && !sqlReserved[name] {
+		return name
+	}
+	q := w.dialect.Quote
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+func sqlDefinition(typ string, notNull bool) string {
+	if notNull {
+		return typ + " NOT NULL"
+	}
+	return typ
+}
+
+// isSQLScalar reports whether the type is stored in a column of its own type
+func isSQLScalar(t meta.Type) bool {
+	return !t.IsArray() && !t.IsMap() && !t.IsObject() && !t.IsInline() && !t.IsNull()
+}
@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed packs
+var packs embed.FS
+
+const packsDir = "packs"
+
+// Template is a template file of a pack, its name tells the language and the output file name,
+// ex. models.ts.tmpl
+type Template struct {
+	Name string
+	Body []byte
+}
+
+// Packs returns the names of the bundled template packs, ex. ts/interface
+func Packs() []string {
+	var names []string
+	_ = fs.WalkDir(packs, packsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := path.Dir(strings.TrimPrefix(p, packsDir+"/"))
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Pack returns the templates of the bundled pack, ex. ts/interface
+func Pack(name string) ([]*Template, error) {
+	dir := path.Join(packsDir, path.Clean(name))
+	entries, err := fs.ReadDir(packs, dir)
+	if err != nil {
+		return nil, errors.Errorf("unknown template pack \"%s\", available packs: %s", name, strings.Join(Packs(), ", "))
+	}
+
+	templates := make([]*Template, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		body, err := fs.ReadFile(packs, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "can't read template \"%s\" of pack \"%s\"", entry.Name(), name)
+		}
+		templates = append(templates, &Template{Name: entry.Name(), Body: body})
+	}
+	if len(templates) == 0 {
+		return nil, errors.Errorf("template pack \"%s\" is empty", name)
+	}
+	return templates, nil
+}